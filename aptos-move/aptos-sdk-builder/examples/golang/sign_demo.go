@@ -0,0 +1,69 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"os"
+	stdlib "testing/aptosstdlib"
+	aptos "testing/aptostypes"
+)
+
+// Usage: sign_demo <hex-encoded ed25519 private key seed>
+//
+// Prints the BCS bytes of a signed coin transfer, e.g. to submit them with
+//
+//	curl -H 'Content-Type: application/x.aptos.signed_transaction+bcs' \
+//		--data-binary @<(sign_demo $KEY | head -1 | xxd -r -p) $NODE/transactions
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: sign_demo <private key>")
+		os.Exit(2)
+	}
+	seed, err := hex.DecodeString(os.Args[1])
+	if err != nil || len(seed) != ed25519.SeedSize {
+		fmt.Fprintln(os.Stderr, "invalid private key: expected 32 hex-encoded bytes")
+		os.Exit(2)
+	}
+	privKey := ed25519.NewKeyFromSeed(seed)
+
+	// The address of a single-key account is the authentication key of its public key.
+	sender := aptos.AuthKeyFromEd25519(privKey.Public().(ed25519.PublicKey))
+	to, err := aptos.ParseAccountAddress("0x2222")
+	if err != nil {
+		panic(err)
+	}
+	coin, err := aptos.ParseTypeTag("0x1::aptos_coin::AptosCoin")
+	if err != nil {
+		panic(err)
+	}
+
+	txn := aptos.RawTransaction{
+		Sender:         sender,
+		SequenceNumber: 0,
+		Payload:        stdlib.EncodeCoinTransfer(coin, to, 1_234_567),
+		MaxGasAmount:   2_000,
+		GasUnitPrice:   1,
+		// A fixed expiration keeps the output deterministic. Real transactions expire
+		// shortly after `time.Now()`.
+		ExpirationTimestampSecs: 1_700_000_000,
+		ChainId:                 aptos.ChainIdTesting,
+	}
+	signed, err := txn.SignEd25519(privKey)
+	if err != nil {
+		panic(fmt.Sprintf("failed to sign: %v", err))
+	}
+	bytes, err := signed.BcsSerialize()
+	if err != nil {
+		panic(fmt.Sprintf("failed to serialize: %v", err))
+	}
+	hash, err := signed.Hash()
+	if err != nil {
+		panic(fmt.Sprintf("failed to hash: %v", err))
+	}
+	fmt.Println(hex.EncodeToString(bytes))
+	fmt.Printf("0x%x\n", hash)
+}
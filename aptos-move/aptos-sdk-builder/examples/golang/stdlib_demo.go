@@ -15,14 +15,14 @@ func demo_coin_transfer() {
 			Address: aptos.AccountAddress(
 				[32]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
 			),
-			Module:     aptos.Identifier("aptos_coin"),
-			Name:       aptos.Identifier("AptosCoin"),
+			Module: aptos.Identifier("aptos_coin"),
+			Name:   aptos.Identifier("AptosCoin"),
 		},
 	}
 
 	to := aptos.AccountAddress(
 		[32]uint8{0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22,
-    0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22},
+			0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22},
 	)
 
 	amount := uint64(1_234_567)
@@ -32,8 +32,8 @@ func demo_coin_transfer() {
 	if err != nil {
 		panic(fmt.Sprintf("failed to decode script: %v", err))
 	}
-	payment := call.(*stdlib.ScriptFunctionCall__CoinTransfer)
-	if payment.Amount != amount || payment.To != to {
+	payment, ok := stdlib.AsCoinTransfer(call)
+	if !ok || payment.Amount != amount || payment.To != to {
 		panic("wrong script content")
 	}
 
@@ -41,12 +41,82 @@ func demo_coin_transfer() {
 	if err != nil {
 		panic("failed to serialize")
 	}
+	// `BcsDeserialize*` functions are generated for every Aptos type. They reject
+	// truncated input as well as trailing bytes.
+	decoded, err := aptos.BcsDeserializeTransactionPayload(bytes)
+	if err != nil {
+		panic(fmt.Sprintf("failed to deserialize: %v", err))
+	}
+	if _, err := stdlib.DecodeScriptFunctionPayload(decoded); err != nil {
+		panic(fmt.Sprintf("failed to decode deserialized payload: %v", err))
+	}
+	if _, err := aptos.BcsDeserializeTransactionPayload(append(bytes, 0)); err == nil {
+		panic("trailing bytes should be rejected")
+	}
 	for _, b := range bytes {
 		fmt.Printf("%d ", b)
 	}
 	fmt.Printf("\n")
 }
 
+func demo_script_function_infos() {
+	for _, info := range stdlib.AllScriptFunctions() {
+		if info.Name != "coin_transfer" {
+			continue
+		}
+		if info.FunctionId() != "0x1::coin::transfer" ||
+			fmt.Sprint(info.TypeParams) != "[CoinType]" ||
+			fmt.Sprint(info.Args) != "[{to address} {amount u64}]" {
+			panic(fmt.Sprintf("wrong descriptor: %+v", info))
+		}
+		return
+	}
+	panic("missing descriptor for coin_transfer")
+}
+
+func demo_decode_to_map() {
+	payload := stdlib.EncodeCoinTransfer(aptos.StructTypeTag(aptos.CoreCodeAddress, "aptos_coin", "AptosCoin"), aptos.CoreCodeAddress, 5)
+	view, err := stdlib.DecodeToMap(payload)
+	if err != nil {
+		panic(err)
+	}
+	if view["function"] != "0x1::coin::transfer" ||
+		fmt.Sprint(view["type_arguments"]) != "[0x1::aptos_coin::AptosCoin]" ||
+		fmt.Sprint(view["arguments"]) != "["+aptos.CoreCodeAddress.ToHex()+" 0x0500000000000000]" {
+		panic(fmt.Sprintf("wrong view: %v", view))
+	}
+}
+
+type countingObserver struct {
+	decoded map[string]int
+	errors  int
+}
+
+func (observer *countingObserver) OnDecoded(name string) { observer.decoded[name]++ }
+func (observer *countingObserver) OnError(err error)     { observer.errors++ }
+
+func demo_decode_observer() {
+	observer := &countingObserver{decoded: make(map[string]int)}
+	registry := stdlib.NewDecoderRegistry()
+	registry.SetObserver(observer)
+
+	payload := stdlib.EncodeCoinTransfer(aptos.StructTypeTag(aptos.CoreCodeAddress, "aptos_coin", "AptosCoin"), aptos.AccountAddress{}, 1)
+	for i := 0; i < 2; i++ {
+		if _, err := registry.Decode(payload); err != nil {
+			panic(err)
+		}
+	}
+	if _, err := registry.Decode(&aptos.TransactionPayload__Script{}); err == nil {
+		panic("expected an error")
+	}
+	if observer.decoded["0x1::coin::transfer"] != 2 || observer.errors != 1 {
+		panic(fmt.Sprintf("wrong events: %+v", observer))
+	}
+}
+
 func main() {
-    demo_coin_transfer()
+	demo_coin_transfer()
+	demo_script_function_infos()
+	demo_decode_observer()
+	demo_decode_to_map()
 }
@@ -0,0 +1,181 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// AccountAddressLength is the number of bytes in an `AccountAddress`.
+const AccountAddressLength = len(AccountAddress{})
+
+// Special addresses, as defined in `account_config` in Rust. Go has no array constants, so
+// these are variables, which must not be modified.
+var (
+	// CoreCodeAddress (0x1) hosts the Move stdlib, the Aptos framework and the token modules.
+	CoreCodeAddress = AccountAddress{31: 0x01}
+	// AptosRootAddress (0xA550C18) is the core resources account of test networks.
+	AptosRootAddress = AccountAddress{28: 0x0a, 29: 0x55, 30: 0x0c, 31: 0x18}
+	// ReservedVMAddress (0x0) is the sender of the transactions run by the VM itself.
+	ReservedVMAddress = AccountAddress{}
+)
+
+// ToHex returns the canonical representation of the address, i.e. "0x" followed by
+// the zero-padded, lowercase hex encoding of all the bytes.
+func (obj AccountAddress) ToHex() string {
+	return "0x" + hex.EncodeToString(obj[:])
+}
+
+// ToHexShort returns the short form of special addresses, i.e. those from 0x0 to 0xf such
+// as 0x1, as a single hex digit, and the `ToHex` form of all the other addresses. Unlike
+// trimming leading zeros, this keeps general addresses unambiguous, e.g. for 0x10.
+func (obj AccountAddress) ToHexShort() string {
+	if obj.isSpecial() {
+		return fmt.Sprintf("0x%x", obj[AccountAddressLength-1])
+	}
+	return obj.ToHex()
+}
+
+// ToHexLiteral returns the form of the address printed by the node and its REST API, i.e.
+// the `ToHex` form without leading zeros, e.g. "0x1" or "0xa550c18", as
+// `AccountAddress::to_hex_literal` in Rust.
+func (obj AccountAddress) ToHexLiteral() string {
+	digits := strings.TrimLeft(hex.EncodeToString(obj[:]), "0")
+	if digits == "" {
+		return "0x0"
+	}
+	return "0x" + digits
+}
+
+// ToHexChecksummed returns the `ToHex` form of the address with a checksum in the case of its
+// letters, in the style of EIP-55: the i-th hex digit is uppercase if the i-th nibble of the
+// SHA3-256 hash of the lowercase digits is 8 or more. `ParseAccountAddress` rejects
+// mixed-case input whose case does not match, which catches most mistyped digits.
+func (obj AccountAddress) ToHexChecksummed() string {
+	digits := []byte(hex.EncodeToString(obj[:]))
+	hash := sha3.Sum256(digits)
+	for i, digit := range digits {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if digit >= 'a' && nibble >= 8 {
+			digits[i] = digit - 'a' + 'A'
+		}
+	}
+	return "0x" + string(digits)
+}
+
+func (obj AccountAddress) isSpecial() bool {
+	for _, b := range obj[:AccountAddressLength-1] {
+		if b != 0 {
+			return false
+		}
+	}
+	return obj[AccountAddressLength-1] < 0x10
+}
+
+// ParseAccountAddress parses an address from a hex string, with or without "0x" prefix.
+// Short forms such as "0x1" are left-padded with zeros. Digits in mixed case must be the
+// full-length `ToHexChecksummed` form of the address.
+func ParseAccountAddress(s string) (AccountAddress, error) {
+	var addr AccountAddress
+	digits := strings.TrimPrefix(s, "0x")
+	if len(digits) == 0 {
+		return addr, fmt.Errorf("invalid account address %q: no hex digits", s)
+	}
+	if len(digits) > 2*AccountAddressLength {
+		return addr, fmt.Errorf("invalid account address %q: too long", s)
+	}
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return addr, fmt.Errorf("invalid account address %q: %v", s, err)
+	}
+	copy(addr[AccountAddressLength-len(decoded):], decoded)
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) {
+		if len(digits) != 2*AccountAddressLength {
+			return AccountAddress{}, fmt.Errorf("invalid account address %q: checksummed addresses must be full length", s)
+		}
+		if digits != strings.TrimPrefix(addr.ToHexChecksummed(), "0x") {
+			return AccountAddress{}, fmt.Errorf("invalid account address %q: wrong checksum", s)
+		}
+	}
+	return addr, nil
+}
+
+// NewAccountAddressFromBytes copies an address from a slice of exactly
+// `AccountAddressLength` bytes.
+func NewAccountAddressFromBytes(b []byte) (AccountAddress, error) {
+	var addr AccountAddress
+	if len(b) != AccountAddressLength {
+		return addr, fmt.Errorf("invalid account address length: expected %d bytes, got %d", AccountAddressLength, len(b))
+	}
+	copy(addr[:], b)
+	return addr, nil
+}
+
+// RandomAccountAddress returns an address read from `crypto/rand`, e.g. for test fixtures.
+func RandomAccountAddress() AccountAddress {
+	addr, err := RandomAccountAddressFrom(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+// RandomAccountAddressFrom reads an address from `r`. Passing a seeded source makes the
+// result deterministic.
+func RandomAccountAddressFrom(r io.Reader) (AccountAddress, error) {
+	var addr AccountAddress
+	if _, err := io.ReadFull(r, addr[:]); err != nil {
+		return AccountAddress{}, err
+	}
+	return addr, nil
+}
+
+// Equal reports whether the two addresses are the same. It is equivalent to `==`.
+func (obj AccountAddress) Equal(other AccountAddress) bool {
+	return obj == other
+}
+
+// Cmp compares the bytes of the two addresses, most significant byte first, and returns
+// -1, 0 or +1. This is the order of addresses in Move and in BCS-encoded sets.
+func (obj AccountAddress) Cmp(other AccountAddress) int {
+	return bytes.Compare(obj[:], other[:])
+}
+
+// AccountAddresses implements `sort.Interface` for a slice of addresses in `Cmp` order,
+// e.g. `sort.Sort(aptostypes.AccountAddresses(signers))`.
+type AccountAddresses []AccountAddress
+
+func (s AccountAddresses) Len() int           { return len(s) }
+func (s AccountAddresses) Less(i, j int) bool { return s[i].Cmp(s[j]) < 0 }
+func (s AccountAddresses) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// MarshalText implements `encoding.TextMarshaler` using the `ToHex` representation, so that
+// addresses can be used in JSON or YAML documents, including as map keys.
+func (obj AccountAddress) MarshalText() ([]byte, error) {
+	return []byte(obj.ToHex()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler` using `ParseAccountAddress`. The
+// address is left unchanged if the text is invalid.
+func (obj *AccountAddress) UnmarshalText(text []byte) error {
+	addr, err := ParseAccountAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*obj = addr
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseAccountAddress(t *testing.T) {
+	one := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	for _, s := range []string{"0x1", "1", "0x01", one, one[2:]} {
+		if addr, err := ParseAccountAddress(s); err != nil || addr != CoreCodeAddress {
+			t.Errorf("%q: expected 0x1, got %v, %v", s, addr, err)
+		}
+	}
+	if CoreCodeAddress.ToHex() != one {
+		t.Fatalf("unexpected hex form %s", CoreCodeAddress.ToHex())
+	}
+	addr := AccountAddress{0: 0xab, 31: 0xcd}
+	if parsed, err := ParseAccountAddress(addr.ToHex()); err != nil || parsed != addr {
+		t.Fatalf("failed to parse %s: %v", addr.ToHex(), err)
+	}
+
+	for _, s := range []string{"", "0x", "0xzz", "0x" + one[2:] + "0", "0x-1"} {
+		if _, err := ParseAccountAddress(s); err == nil {
+			t.Errorf("accepted the address %q", s)
+		}
+	}
+}
+
+func TestChecksummedAccountAddress(t *testing.T) {
+	addr, err := ParseAccountAddress("0x7df415e5b21bdaa8b2946e8f1f4278b39904e51a69627494cd3e6f2996732fbd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksummed := addr.ToHexChecksummed()
+	if strings.ToLower(checksummed) != addr.ToHex() || checksummed == addr.ToHex() {
+		t.Fatalf("unexpected checksummed form %s", checksummed)
+	}
+	if parsed, err := ParseAccountAddress(checksummed); err != nil || parsed != addr {
+		t.Fatalf("unable to parse %s: %v", checksummed, err)
+	}
+
+	// Flip the case of the first letter.
+	i := strings.IndexAny(checksummed[2:], "abcdefABCDEF") + 2
+	corrupted := checksummed[:i] + string(checksummed[i]^0x20) + checksummed[i+1:]
+	if _, err := ParseAccountAddress(corrupted); err == nil || !strings.Contains(err.Error(), "wrong checksum") {
+		t.Fatalf("expected a checksum error for %s, got %v", corrupted, err)
+	}
+
+	// Short forms have no checksum: they are accepted in a single case only.
+	short := AccountAddress{30: 0x0a, 31: 0xbc}
+	for _, s := range []string{"0xabc", "0xABC", short.ToHexChecksummed()} {
+		if parsed, err := ParseAccountAddress(s); err != nil || parsed != short {
+			t.Errorf("unable to parse %s: %v", s, err)
+		}
+	}
+	if _, err := ParseAccountAddress("0xAbC"); err == nil || !strings.Contains(err.Error(), "must be full length") {
+		t.Fatalf("expected a mixed-case short address to be rejected, got %v", err)
+	}
+}
+
+func TestAccountAddressCmp(t *testing.T) {
+	two := AccountAddress{31: 2}
+	large := AccountAddress{30: 1}
+	if two.Cmp(large) != -1 || large.Cmp(two) != 1 || two.Cmp(two) != 0 {
+		t.Fatal("0x2 should sort before 0x100")
+	}
+	if !two.Equal(AccountAddress{31: 2}) || two.Equal(large) {
+		t.Fatal("unexpected equality")
+	}
+	addresses := []AccountAddress{large, CoreCodeAddress, two, ReservedVMAddress}
+	sort.Sort(AccountAddresses(addresses))
+	if want := []AccountAddress{ReservedVMAddress, CoreCodeAddress, two, large}; !reflect.DeepEqual(addresses, want) {
+		t.Fatalf("unexpected order %v", addresses)
+	}
+}
+
+func TestAccountAddressText(t *testing.T) {
+	two := AccountAddress{31: 2}
+	output, err := json.Marshal(map[AccountAddress]int{two: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"` + two.ToHex() + `":3}`; string(output) != want {
+		t.Fatalf("expected %s, got %s", want, output)
+	}
+	var decoded map[AccountAddress]int
+	if err := json.Unmarshal(output, &decoded); err != nil || decoded[two] != 3 {
+		t.Fatalf("failed to decode %s: %v", output, err)
+	}
+	if err := json.Unmarshal([]byte(`{"0x1":1}`), &decoded); err != nil || decoded[CoreCodeAddress] != 1 {
+		t.Fatalf("failed to decode a short address: %v", err)
+	}
+
+	addr := two
+	if err := json.Unmarshal([]byte(`"0xzz"`), &addr); err == nil || addr != two {
+		t.Fatalf("an invalid address gave %v and changed the address to %v", err, addr)
+	}
+}
+
+func TestRandomAccountAddress(t *testing.T) {
+	a, err := RandomAccountAddressFrom(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := RandomAccountAddressFrom(rand.New(rand.NewSource(1))); err != nil || a != b {
+		t.Fatal("a seeded source should give the same address")
+	}
+	if _, err := RandomAccountAddressFrom(bytes.NewReader(make([]byte, AccountAddressLength-1))); err == nil {
+		t.Fatal("accepted a short read")
+	}
+	if RandomAccountAddress() == RandomAccountAddress() {
+		t.Fatal("two random addresses are equal")
+	}
+
+	if addr, err := NewAccountAddressFromBytes(a[:]); err != nil || addr != a {
+		t.Fatalf("failed to copy %v: %v", a, err)
+	}
+	for _, n := range []int{0, 16, 33} {
+		if _, err := NewAccountAddressFromBytes(make([]byte, n)); err == nil {
+			t.Errorf("accepted %d bytes", n)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// OctasPerAPT is the number of octas, the smallest unit of the Aptos coin, in one APT.
+const OctasPerAPT = 100_000_000
+
+// Largest number of octas that a float64 holds exactly.
+const maxExactFloatOctas = 1 << 53
+
+// Amount is a quantity of Aptos coins. Unlike a plain `uint64`, it cannot be mistaken for a
+// number of whole APT: build it with `AmountFromOcta` or `AmountFromAPT`, and pass
+// `Octas()` to the generated encoders.
+type Amount struct {
+	octas uint64
+}
+
+// AmountFromOcta returns the amount of `octas` octas.
+func AmountFromOcta(octas uint64) Amount {
+	return Amount{octas: octas}
+}
+
+// AmountFromAPT returns the amount of `apt` APT. It fails if the amount is negative, has
+// more than 8 decimals, or has too many digits to be converted from a float64 exactly,
+// i.e. is above 2^53 octas (about 90 million APT). Use `AmountFromOcta` for larger
+// amounts.
+func AmountFromAPT(apt float64) (Amount, error) {
+	if math.IsNaN(apt) || apt < 0 {
+		return Amount{}, fmt.Errorf("invalid APT amount: %v", apt)
+	}
+	octas := apt * OctasPerAPT
+	if octas > maxExactFloatOctas {
+		return Amount{}, fmt.Errorf("invalid APT amount %v: too large to convert exactly", apt)
+	}
+	rounded := math.Round(octas)
+	if math.Abs(octas-rounded) > 1e-3 {
+		return Amount{}, fmt.Errorf("invalid APT amount %v: more than 8 decimals", apt)
+	}
+	return Amount{octas: uint64(rounded)}, nil
+}
+
+// Octas returns the amount in octas, as taken by the generated encoders.
+func (obj Amount) Octas() uint64 {
+	return obj.octas
+}
+
+// String returns the exact amount in APT, e.g. "1.5 APT".
+func (obj Amount) String() string {
+	whole, fraction := obj.octas/OctasPerAPT, obj.octas%OctasPerAPT
+	if fraction == 0 {
+		return fmt.Sprintf("%d APT", whole)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%08d", whole, fraction), "0") + " APT"
+}
+
+// ErrU64Overflow is returned by `AddU64Checked` and `SumU64` when the result does not fit in a
+// `uint64`, which Move would abort on.
+var ErrU64Overflow = errors.New("u64 overflow")
+
+// AddU64Checked returns `a + b`, or `ErrU64Overflow` instead of a wrapped-around sum.
+func AddU64Checked(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, ErrU64Overflow
+	}
+	return sum, nil
+}
+
+// SumU64 returns the sum of `values`, e.g. the total of the amounts of a batch transfer, or
+// `ErrU64Overflow` if it does not fit in a `uint64`.
+func SumU64(values []uint64) (uint64, error) {
+	var total uint64
+	for _, value := range values {
+		var err error
+		if total, err = AddU64Checked(total, value); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAmountFromAPT(t *testing.T) {
+	for _, test := range []struct {
+		apt   float64
+		octas uint64
+		str   string
+	}{
+		{0, 0, "0 APT"},
+		{1, 100_000_000, "1 APT"},
+		{0.1, 10_000_000, "0.1 APT"},
+		{0.3, 30_000_000, "0.3 APT"},
+		{0.00000001, 1, "0.00000001 APT"},
+		{1.23456789, 123_456_789, "1.23456789 APT"},
+		// 2^53 octas, the largest amount converted exactly.
+		{90071992.54740992, 1 << 53, "90071992.54740992 APT"},
+	} {
+		amount, err := AmountFromAPT(test.apt)
+		if err != nil || amount.Octas() != test.octas || amount.String() != test.str {
+			t.Errorf("%v: expected %d octas (%s), got %d (%v), %v", test.apt, test.octas, test.str, amount.Octas(), amount, err)
+		}
+	}
+
+	for _, apt := range []float64{
+		math.Nextafter(90071992.54740992, math.Inf(1)),
+		math.Inf(1),
+		math.Inf(-1),
+		math.NaN(),
+		-1,
+		0.000000001,
+		1.000000001,
+	} {
+		if amount, err := AmountFromAPT(apt); err == nil {
+			t.Errorf("%v: accepted as %d octas", apt, amount.Octas())
+		}
+	}
+
+	if amount := AmountFromOcta(math.MaxUint64); amount.String() != "184467440737.09551615 APT" {
+		t.Fatalf("unexpected amount %v", amount)
+	}
+}
+
+func TestAddU64Checked(t *testing.T) {
+	if sum, err := AddU64Checked(math.MaxUint64-1, 1); err != nil || sum != math.MaxUint64 {
+		t.Fatalf("expected MaxUint64, got %d, %v", sum, err)
+	}
+	if sum, err := AddU64Checked(math.MaxUint64, 0); err != nil || sum != math.MaxUint64 {
+		t.Fatalf("expected MaxUint64, got %d, %v", sum, err)
+	}
+	if _, err := AddU64Checked(math.MaxUint64, 1); !errors.Is(err, ErrU64Overflow) {
+		t.Fatalf("expected an overflow, got %v", err)
+	}
+	if _, err := AddU64Checked(math.MaxUint64, math.MaxUint64); !errors.Is(err, ErrU64Overflow) {
+		t.Fatalf("expected an overflow, got %v", err)
+	}
+
+	if sum, err := SumU64(nil); err != nil || sum != 0 {
+		t.Fatalf("expected 0, got %d, %v", sum, err)
+	}
+	if sum, err := SumU64([]uint64{1 << 63, 1<<63 - 1}); err != nil || sum != math.MaxUint64 {
+		t.Fatalf("expected MaxUint64, got %d, %v", sum, err)
+	}
+	if _, err := SumU64([]uint64{1 << 63, 1 << 63, 0}); !errors.Is(err, ErrU64Overflow) {
+		t.Fatalf("expected an overflow, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// The arguments of a `ScriptFunction` are a Move `vector<vector<u8>>` where each element is
+// the BCS encoding of one argument. The `Arg*` helpers below return such an element, so that
+// hand-built calls do not need a serializer:
+//
+//	args := [][]byte{aptostypes.ArgAddress(to), aptostypes.ArgU64(amount)}
+//	call, err := aptostypes.NewScriptFunction("0x1::coin::transfer", typeArgs, args)
+//
+// The elements must not be framed again: `ScriptFunction.Args` adds the outer length
+// prefixes when the call is serialized.
+
+// ArgBool encodes a Move `bool` argument.
+func ArgBool(value bool) []byte {
+	if value {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// ArgU8 encodes a Move `u8` argument.
+func ArgU8(value uint8) []byte {
+	return []byte{value}
+}
+
+// ArgU64 encodes a Move `u64` argument.
+func ArgU64(value uint64) []byte {
+	s := NewSerializerWithCapacity(8)
+	_ = s.SerializeU64(value)
+	return s.GetBytes()
+}
+
+// ArgU128 encodes a Move `u128` argument.
+func ArgU128(value serde.Uint128) []byte {
+	s := NewSerializerWithCapacity(16)
+	_ = s.SerializeU128(value)
+	return s.GetBytes()
+}
+
+// ArgAddress encodes a Move `address` argument.
+func ArgAddress(value AccountAddress) []byte {
+	return append([]byte(nil), value[:]...)
+}
+
+// ArgU8Vector encodes a Move `vector<u8>` argument, i.e. `value` with its length prefix.
+// It panics if `value` exceeds the maximum length of a BCS sequence.
+func ArgU8Vector(value []byte) []byte {
+	s := NewSerializerWithCapacity(len(value) + 5)
+	if err := s.SerializeBytes(value); err != nil {
+		panic("unable to serialize argument of type vector<u8>: " + err.Error())
+	}
+	return s.GetBytes()
+}
+
+// EncodeArgs returns the BCS encoding of the whole `vector<vector<u8>>` made of the given
+// arguments, each already encoded, e.g. by the `Arg*` helpers. This is the byte string found
+// in a serialized `ScriptFunction` after its type arguments. It panics if there are too many
+// arguments or if one of them is too long.
+func EncodeArgs(args ...[]byte) []byte {
+	bytes, err := BcsSerializeBytesVector(args)
+	if err != nil {
+		panic("unable to serialize arguments: " + err.Error())
+	}
+	return bytes
+}
+
+// DecodeArguments decodes the arguments of a call to a script function unknown to this
+// package, given the Move types of its parameters, e.g. `address` and `u64` for a transfer.
+// The values have the Go types taken by the generated encoders: `bool`, `uint8`, `uint64`,
+// `serde.Uint128`, `AccountAddress`, `[]byte`, and slices of those for other vectors.
+func DecodeArguments(args [][]byte, types []TypeTag) ([]interface{}, error) {
+	if len(args) != len(types) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(types), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		var err error
+		if values[i], err = DecodeArgument(arg, types[i]); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+	}
+	return values, nil
+}
+
+// DecodeArgument decodes a single argument of type `typ`, see `DecodeArguments`.
+func DecodeArgument(arg []byte, typ TypeTag) (interface{}, error) {
+	switch typ := typ.(type) {
+	case *TypeTag__Bool:
+		return decodeArgument(arg, func(d *Deserializer) (interface{}, error) { return d.DeserializeBool() })
+	case *TypeTag__U8:
+		return decodeArgument(arg, func(d *Deserializer) (interface{}, error) { return d.DeserializeU8() })
+	case *TypeTag__U64:
+		return decodeArgument(arg, func(d *Deserializer) (interface{}, error) { return d.DeserializeU64() })
+	case *TypeTag__U128:
+		return decodeArgument(arg, func(d *Deserializer) (interface{}, error) { return d.DeserializeU128() })
+	case *TypeTag__Address:
+		return decodeArgument(arg, func(d *Deserializer) (interface{}, error) { return DeserializeAccountAddress(d) })
+	case *TypeTag__Vector:
+		switch item := typ.Value.(type) {
+		case *TypeTag__Bool:
+			return BcsDeserializeBoolVector(arg)
+		case *TypeTag__U8:
+			return decodeArgument(arg, func(d *Deserializer) (interface{}, error) { return d.DeserializeBytes() })
+		case *TypeTag__U64:
+			return BcsDeserializeU64Vector(arg)
+		case *TypeTag__U128:
+			return BcsDeserializeU128Vector(arg)
+		case *TypeTag__Address:
+			return BcsDeserializeAddressVector(arg)
+		case *TypeTag__Vector:
+			if _, ok := item.Value.(*TypeTag__U8); ok {
+				return BcsDeserializeBytesVector(arg)
+			}
+		}
+	}
+	return nil, fmt.Errorf("unsupported argument type %v", typ)
+}
+
+// Decode a whole argument with `deserialize`, rejecting trailing bytes.
+func decodeArgument(arg []byte, deserialize func(*Deserializer) (interface{}, error)) (interface{}, error) {
+	d := NewDeserializer(arg, DefaultDeserializerOptions)
+	value, err := deserialize(d)
+	if err != nil {
+		return nil, err
+	}
+	if d.Remaining() > 0 {
+		return nil, errors.New("some input bytes were not read")
+	}
+	return value, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+func TestEncodeArgs(t *testing.T) {
+	to := AccountAddress{31: 2}
+	encoded := EncodeArgs(
+		ArgBool(true),
+		ArgU8(7),
+		ArgU64(0x0102),
+		ArgU128(serde.Uint128{High: 1, Low: 2}),
+		ArgAddress(to),
+		ArgU8Vector([]byte{0xab, 0xcd}),
+	)
+	args, err := BcsDeserializeBytesVector(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range [][]byte{
+		{1},
+		{7},
+		{2, 1, 0, 0, 0, 0, 0, 0},
+		{2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0},
+		to[:],
+		{2, 0xab, 0xcd},
+	} {
+		if i >= len(args) || !bytes.Equal(args[i], want) {
+			t.Fatalf("argument %d: expected %x, got %x", i, want, args)
+		}
+	}
+	if len(args) != 6 {
+		t.Fatalf("expected 6 arguments, got %d", len(args))
+	}
+
+	types := []TypeTag{BoolTypeTag, U8TypeTag, U64TypeTag, U128TypeTag, AddressTypeTag, VectorTypeTag(U8TypeTag)}
+	values, err := DecodeArguments(args, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{true, uint8(7), uint64(0x0102), serde.Uint128{High: 1, Low: 2}, to, []byte{0xab, 0xcd}}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	if _, err := DecodeArguments(args[1:], types); err == nil {
+		t.Fatal("accepted a missing argument")
+	}
+	args[2] = append(args[2], 0)
+	if _, err := DecodeArguments(args, types); err == nil {
+		t.Fatal("accepted an argument with a trailing byte")
+	}
+}
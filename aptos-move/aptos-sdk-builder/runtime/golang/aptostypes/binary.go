@@ -0,0 +1,97 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "fmt"
+
+// The `encoding.BinaryMarshaler` and `encoding.BinaryUnmarshaler` implementations below use
+// the BCS encoding, byte for byte. Since `TransactionPayload` is an interface, its variants
+// implement them individually; use `BcsDeserializeTransactionPayload` to decode a payload
+// of unknown kind.
+
+func (obj *Script) MarshalBinary() ([]byte, error) {
+	return obj.BcsSerialize()
+}
+
+func (obj *Script) UnmarshalBinary(data []byte) error {
+	value, err := BcsDeserializeScript(data)
+	if err != nil {
+		return err
+	}
+	*obj = value
+	return nil
+}
+
+func (obj *SignedTransaction) MarshalBinary() ([]byte, error) {
+	return obj.BcsSerialize()
+}
+
+func (obj *SignedTransaction) UnmarshalBinary(data []byte) error {
+	value, err := BcsDeserializeSignedTransaction(data)
+	if err != nil {
+		return err
+	}
+	*obj = value
+	return nil
+}
+
+func (obj *TransactionPayload__WriteSet) MarshalBinary() ([]byte, error) {
+	return obj.BcsSerialize()
+}
+
+func (obj *TransactionPayload__WriteSet) UnmarshalBinary(data []byte) error {
+	payload, err := unmarshalTransactionPayload(data, obj)
+	if err == nil {
+		*obj = *payload.(*TransactionPayload__WriteSet)
+	}
+	return err
+}
+
+func (obj *TransactionPayload__Script) MarshalBinary() ([]byte, error) {
+	return obj.BcsSerialize()
+}
+
+func (obj *TransactionPayload__Script) UnmarshalBinary(data []byte) error {
+	payload, err := unmarshalTransactionPayload(data, obj)
+	if err == nil {
+		*obj = *payload.(*TransactionPayload__Script)
+	}
+	return err
+}
+
+func (obj *TransactionPayload__ModuleBundle) MarshalBinary() ([]byte, error) {
+	return obj.BcsSerialize()
+}
+
+func (obj *TransactionPayload__ModuleBundle) UnmarshalBinary(data []byte) error {
+	payload, err := unmarshalTransactionPayload(data, obj)
+	if err == nil {
+		*obj = *payload.(*TransactionPayload__ModuleBundle)
+	}
+	return err
+}
+
+func (obj *TransactionPayload__ScriptFunction) MarshalBinary() ([]byte, error) {
+	return obj.BcsSerialize()
+}
+
+func (obj *TransactionPayload__ScriptFunction) UnmarshalBinary(data []byte) error {
+	payload, err := unmarshalTransactionPayload(data, obj)
+	if err == nil {
+		*obj = *payload.(*TransactionPayload__ScriptFunction)
+	}
+	return err
+}
+
+// Decode a payload and check that it is the same variant as `want`.
+func unmarshalTransactionPayload(data []byte, want TransactionPayload) (TransactionPayload, error) {
+	payload, err := BcsDeserializeTransactionPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	if fmt.Sprintf("%T", payload) != fmt.Sprintf("%T", want) {
+		return nil, fmt.Errorf("expected a %T payload, got %T", want, payload)
+	}
+	return payload, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"encoding"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	payload := coinTransferPayload().(*TransactionPayload__ScriptFunction)
+	var _ encoding.BinaryMarshaler = payload
+	var _ encoding.BinaryUnmarshaler = payload
+	output, err := payload.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, err := payload.BcsSerialize(); err != nil || !bytes.Equal(output, want) {
+		t.Fatalf("expected the BCS encoding %x, got %x", want, output)
+	}
+	// Decoded type tags have empty rather than nil type arguments, so compare the encodings.
+	var decoded TransactionPayload__ScriptFunction
+	if err := decoded.UnmarshalBinary(output); err != nil {
+		t.Fatal(err)
+	}
+	if encoded, err := decoded.BcsSerialize(); err != nil || !bytes.Equal(encoded, output) {
+		t.Fatalf("decoding %x gave %x", output, encoded)
+	}
+
+	// Another variant, or trailing bytes.
+	var script TransactionPayload__Script
+	if err := script.UnmarshalBinary(output); err == nil {
+		t.Fatal("decoded a script function call as a script")
+	}
+	if err := decoded.UnmarshalBinary(append(output, 0)); err == nil {
+		t.Fatal("accepted a trailing byte")
+	}
+
+	signed := SignedTransaction{RawTxn: RawTransaction{Payload: payload}, Authenticator: &TransactionAuthenticator__Ed25519{}}
+	if output, err = signed.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	var decodedSigned SignedTransaction
+	if err := decodedSigned.UnmarshalBinary(output); err != nil {
+		t.Fatal(err)
+	}
+	if encoded, err := decodedSigned.MarshalBinary(); err != nil || !bytes.Equal(encoded, output) {
+		t.Fatalf("decoding %x gave %x", output, encoded)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Reserved chain IDs, as defined by `NamedChain` in Rust.
+const (
+	ChainIdMainnet    ChainId = 1
+	ChainIdTestnet    ChainId = 2
+	ChainIdDevnet     ChainId = 3
+	ChainIdTesting    ChainId = 4
+	ChainIdPremainnet ChainId = 5
+)
+
+var chainIdNames = map[ChainId]string{
+	ChainIdMainnet:    "MAINNET",
+	ChainIdTestnet:    "TESTNET",
+	ChainIdDevnet:     "DEVNET",
+	ChainIdTesting:    "TESTING",
+	ChainIdPremainnet: "PREMAINNET",
+}
+
+// ParseChainId parses either the name of a reserved chain, e.g. "testnet", or a non-zero
+// numeric chain ID. Names are case-insensitive.
+func ParseChainId(s string) (ChainId, error) {
+	name := strings.ToUpper(s)
+	for id, reserved := range chainIdNames {
+		if name == reserved {
+			return id, nil
+		}
+	}
+	value, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chain id %q: not a reserved chain or a number", s)
+	}
+	if value == 0 {
+		return 0, fmt.Errorf("invalid chain id %q: cannot be 0", s)
+	}
+	return ChainId(value), nil
+}
+
+// String returns the name of a reserved chain, e.g. "TESTNET", or the number otherwise.
+func (obj ChainId) String() string {
+	if name, ok := chainIdNames[obj]; ok {
+		return name
+	}
+	return strconv.Itoa(int(obj))
+}
@@ -0,0 +1,36 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "testing"
+
+func TestParseChainId(t *testing.T) {
+	for s, want := range map[string]ChainId{
+		"mainnet": ChainIdMainnet,
+		"TESTNET": ChainIdTestnet,
+		"Devnet":  ChainIdDevnet,
+		"testing": ChainIdTesting,
+		"4":       ChainIdTesting,
+		"33":      33,
+		"255":     255,
+	} {
+		if id, err := ParseChainId(s); err != nil || id != want {
+			t.Errorf("%q: expected %d, got %d, %v", s, want, id, err)
+		}
+	}
+	for _, s := range []string{"", "0", "256", "-1", "main", "0x2"} {
+		if id, err := ParseChainId(s); err == nil {
+			t.Errorf("%q: accepted as %d", s, id)
+		}
+	}
+
+	if ChainIdPremainnet.String() != "PREMAINNET" || ChainId(9).String() != "9" {
+		t.Fatalf("unexpected names %s and %s", ChainIdPremainnet, ChainId(9))
+	}
+	for id := range chainIdNames {
+		if parsed, err := ParseChainId(id.String()); err != nil || parsed != id {
+			t.Errorf("%s: failed to parse its name: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "context"
+
+// Byte vectors are copied in chunks of this size by `BcsSerializeContext`, and the context
+// is checked before each chunk.
+const serializeChunkSize = 64 * 1024
+
+// BcsSerializeContext returns the BCS encoding of `value`, or `ctx.Err()` if the context is
+// cancelled before the encoding is complete. The context is checked at every struct or enum
+// and periodically while writing large byte vectors, e.g. the modules of a `ModuleBundle`.
+func BcsSerializeContext(ctx context.Context, value Serializable) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := &contextSerializer{Serializer: new(Serializer), ctx: ctx}
+	if err := value.Serialize(s); err != nil {
+		return nil, err
+	}
+	return s.GetBytes(), nil
+}
+
+func (obj *RawTransaction) BcsSerializeContext(ctx context.Context) ([]byte, error) {
+	return BcsSerializeContext(ctx, obj)
+}
+
+func (obj *SignedTransaction) BcsSerializeContext(ctx context.Context) ([]byte, error) {
+	return BcsSerializeContext(ctx, obj)
+}
+
+type contextSerializer struct {
+	*Serializer
+	ctx context.Context
+}
+
+func (s *contextSerializer) IncreaseContainerDepth() error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	return s.Serializer.IncreaseContainerDepth()
+}
+
+func (s *contextSerializer) SerializeBytes(value []byte) error {
+	if err := s.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	for len(value) > 0 {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		n := len(value)
+		if n > serializeChunkSize {
+			n = serializeChunkSize
+		}
+		s.buf = append(s.buf, value[:n]...)
+		value = value[n:]
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// A context cancelled after `Err` has been called `checks` times.
+type cancelAfterContext struct {
+	context.Context
+	checks int
+}
+
+func (ctx *cancelAfterContext) Err() error {
+	if ctx.checks == 0 {
+		return context.Canceled
+	}
+	ctx.checks--
+	return nil
+}
+
+func TestBcsSerializeContext(t *testing.T) {
+	module := bytes.Repeat([]byte{7}, 4*serializeChunkSize+3)
+	payload := &TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{Code: module}, {Code: []byte{1}}}}}
+	want, err := payload.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output, err := BcsSerializeContext(context.Background(), payload); err != nil || !bytes.Equal(output, want) {
+		t.Fatalf("unexpected encoding: %v", err)
+	}
+	raw := RawTransaction{Payload: payload}
+	if want, err = raw.BcsSerialize(); err != nil {
+		t.Fatal(err)
+	}
+	if output, err := raw.BcsSerializeContext(context.Background()); err != nil || !bytes.Equal(output, want) {
+		t.Fatalf("unexpected encoding: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := BcsSerializeContext(ctx, payload); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled context, got %v", err)
+	}
+	// Cancelled after the first chunk of the large module, once the context has been checked
+	// on entry and for the payload, the bundle and the module.
+	if _, err := BcsSerializeContext(&cancelAfterContext{context.Background(), 5}, payload); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled context, got %v", err)
+	}
+}
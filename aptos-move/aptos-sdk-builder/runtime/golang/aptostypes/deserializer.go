@@ -0,0 +1,493 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// DeserializerOptions configures a `Deserializer`. The zero value gives the same limits
+// as `bcs.NewDeserializer` and the Rust BCS runtime.
+type DeserializerOptions struct {
+	// MaxContainerDepth bounds the nesting of structs and enum variants.
+	// Zero means `bcs.MaxContainerDepth`.
+	MaxContainerDepth uint64
+	// MaxSequenceLength bounds the length of vectors and strings.
+	// Zero means `bcs.MaxSequenceLength`.
+	MaxSequenceLength uint64
+	// MaxInputLength bounds the number of bytes pulled from the reader of a deserializer
+	// created by `NewDeserializerFromReader`, and with it the memory allocated for untrusted
+	// input: the length of a sequence cannot exceed the input left. Zero means no limit.
+	// In-memory inputs are already bounded by their size.
+	MaxInputLength int
+	// ZeroCopy makes byte vectors alias the input instead of being copied, which saves an
+	// allocation per `vector<u8>`, e.g. in read-only indexers. The decoded values are then
+	// only valid as long as the input is neither modified nor reused, and modifying them
+	// modifies the input. Appending to them never does, as their capacity is capped.
+	ZeroCopy bool
+}
+
+// DefaultDeserializerOptions are the options of the deserializers created by the functions
+// of this package that decode a byte slice or a reader, e.g. the generated `BcsDeserialize*`
+// functions, `BcsDeserializeSignedTransactionFrom` and the script decoders of the generated
+// stdlib. Set them at startup, not while inputs are being decoded.
+var DefaultDeserializerOptions DeserializerOptions
+
+// ErrMalformedInput is the error wrapped by a `DeserializeError` when the input is not a
+// valid BCS encoding, as opposed to an error of the underlying reader.
+var ErrMalformedInput = errors.New("malformed BCS input")
+
+// ErrUnknownVariant is wrapped by the error of the generated `Deserialize*` functions of
+// enums, e.g. `DeserializeTypeTag`, when the variant index is not one of the known variants,
+// e.g. a variant added by a newer version of the node.
+var ErrUnknownVariant = errors.New("unknown variant")
+
+// ErrLimitExceeded is wrapped by a `DeserializeError` when the input is valid BCS but
+// exceeds one of the `DeserializerOptions`.
+var ErrLimitExceeded = errors.New("BCS deserializer limit exceeded")
+
+// The following errors refine `ErrMalformedInput`: `errors.Is(err, ErrMalformedInput)` holds
+// for them as well.
+var (
+	// ErrTruncated is wrapped when the input ends in the middle of a value, or is shorter
+	// than a sequence length. The end of a stream is reported as `io.ErrUnexpectedEOF`.
+	ErrTruncated error = &malformedKind{"truncated BCS input"}
+	// ErrNonCanonical is wrapped when a value is not in its canonical BCS encoding, e.g. a
+	// bool byte other than 0 or 1, a uleb128 number with trailing zero digits or map keys
+	// out of order.
+	ErrNonCanonical error = &malformedKind{"non-canonical BCS input"}
+)
+
+type malformedKind struct {
+	msg string
+}
+
+func (e *malformedKind) Error() string {
+	return e.msg
+}
+
+func (e *malformedKind) Is(target error) bool {
+	return target == ErrMalformedInput
+}
+
+// DeserializeError is returned by the methods of `Deserializer` when a value cannot be read.
+// It unwraps to `ErrMalformedInput`, `ErrTruncated`, `ErrNonCanonical`, `ErrLimitExceeded`
+// or to the error of the reader, e.g. `io.ErrUnexpectedEOF`.
+type DeserializeError struct {
+	// Offset of the first byte of the value that could not be read.
+	Offset int
+	// Description of the failure, e.g. "reading sequence length: unexpected end of input".
+	Msg string
+	Err error
+}
+
+func (e *DeserializeError) Error() string {
+	return fmt.Sprintf("failed at offset %d %s", e.Offset, e.Msg)
+}
+
+func (e *DeserializeError) Unwrap() error {
+	return e.Err
+}
+
+// An error in the input itself, as opposed to an error of the reader. `kind` is the error
+// wrapped by the resulting `DeserializeError`.
+type malformedError struct {
+	msg  string
+	kind error
+}
+
+func (e malformedError) Error() string {
+	return e.msg
+}
+
+// Deserializer is a BCS implementation of `serde.Deserializer` with configurable limits.
+// It can be passed to any of the generated `Deserialize*` functions, e.g.
+//
+//	d := aptostypes.NewDeserializer(input, aptostypes.DeserializerOptions{MaxContainerDepth: 32})
+//	payload, err := aptostypes.DeserializeTransactionPayload(d)
+type Deserializer struct {
+	// In streaming mode, `input` holds the bytes read from `reader` so far.
+	input             []byte
+	reader            io.Reader
+	offset            int
+	depth             uint64
+	maxDepth          uint64
+	maxSequenceLength uint64
+	maxInputLength    int
+	zeroCopy          bool
+}
+
+var _ serde.Deserializer = (*Deserializer)(nil)
+
+// NewDeserializer creates a deserializer reading from `input`.
+func NewDeserializer(input []byte, options DeserializerOptions) *Deserializer {
+	maxDepth := options.MaxContainerDepth
+	if maxDepth == 0 {
+		maxDepth = bcs.MaxContainerDepth
+	}
+	maxSequenceLength := options.MaxSequenceLength
+	if maxSequenceLength == 0 {
+		maxSequenceLength = bcs.MaxSequenceLength
+	}
+	return &Deserializer{
+		input:             input,
+		maxDepth:          maxDepth,
+		maxSequenceLength: maxSequenceLength,
+		maxInputLength:    options.MaxInputLength,
+		zeroCopy:          options.ZeroCopy,
+	}
+}
+
+// NewDeserializerFromReader creates a deserializer pulling bytes from `r` as they are
+// needed, so that values can be decoded one at a time from a stream. Nothing is read past
+// the end of the decoded value and `GetBufferOffset` gives the number of bytes consumed.
+//
+// If the stream ends in the middle of a value, deserialization fails with an error wrapping
+// `io.ErrUnexpectedEOF`. If it ends before the first byte, it fails with `io.EOF`.
+func NewDeserializerFromReader(r io.Reader, options DeserializerOptions) *Deserializer {
+	d := NewDeserializer(nil, options)
+	d.reader = r
+	return d
+}
+
+// Wrap an error that happened while reading the value starting at `offset`.
+func (d *Deserializer) fail(offset int, what string, err error) error {
+	// Errors are only wrapped once, and the end of a stream before its first byte is
+	// reported as is.
+	if _, ok := err.(*DeserializeError); ok || err == io.EOF {
+		return err
+	}
+	msg := fmt.Sprintf("reading %s: %v", what, err)
+	if err, ok := err.(malformedError); ok {
+		return &DeserializeError{Offset: offset, Msg: msg, Err: err.kind}
+	}
+	return &DeserializeError{Offset: offset, Msg: msg, Err: err}
+}
+
+func (d *Deserializer) IncreaseContainerDepth() error {
+	if d.depth >= d.maxDepth {
+		err := fmt.Errorf("max container depth of %d exceeded: %w", d.maxDepth, ErrLimitExceeded)
+		return d.fail(d.offset, "container", err)
+	}
+	d.depth++
+	return nil
+}
+
+func (d *Deserializer) DecreaseContainerDepth() {
+	d.depth--
+}
+
+// GetBufferOffset returns the number of bytes consumed so far.
+func (d *Deserializer) GetBufferOffset() uint64 {
+	return uint64(d.offset)
+}
+
+// Remaining returns the number of input bytes after the current offset. It is always zero
+// for a deserializer created by `NewDeserializerFromReader`, which reads no bytes in advance.
+func (d *Deserializer) Remaining() int {
+	return len(d.input) - d.offset
+}
+
+// Offset returns the number of bytes consumed so far, like `GetBufferOffset`.
+func (d *Deserializer) Offset() int {
+	return d.offset
+}
+
+// RemainingBytes returns the input bytes after the current offset, e.g. the next frame once
+// a value has been decoded from the start of the input. The slice aliases the input buffer:
+// callers must not modify it. Like `Remaining`, it is always empty for a deserializer created
+// by `NewDeserializerFromReader`.
+func (d *Deserializer) RemainingBytes() []byte {
+	return d.input[d.offset:]
+}
+
+// Skip advances the offset by `n` bytes without decoding them. Together with the
+// `Deserialize*` methods, this lets callers parse only the fields they need, e.g. the sender
+// and the sequence number at the start of a `RawTransaction`:
+//
+//	d := aptostypes.NewDeserializer(input, aptostypes.DeserializerOptions{})
+//	sender, err := aptostypes.DeserializeAccountAddress(d)
+//	...
+//	sequenceNumber, err := d.DeserializeU64()
+func (d *Deserializer) Skip(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid number of bytes to skip: %d", n)
+	}
+	_, err := d.readValue(uint64(n), "skipped bytes")
+	return err
+}
+
+// Bytes are pulled from a reader in chunks of at most this size, so that a large length
+// prefix does not cause a large allocation before the data is actually received.
+const readChunkSize = 64 * 1024
+
+// Make sure that at least `n` bytes are available after the current offset.
+func (d *Deserializer) fill(n uint64) error {
+	if d.maxInputLength > 0 && n > uint64(d.maxInputLength-d.offset) {
+		return fmt.Errorf("input exceeds %d bytes: %w", d.maxInputLength, ErrLimitExceeded)
+	}
+	for uint64(len(d.input)-d.offset) < n {
+		chunk := n - uint64(len(d.input)-d.offset)
+		if chunk > readChunkSize {
+			chunk = readChunkSize
+		}
+		start := len(d.input)
+		d.input = append(d.input, make([]byte, chunk)...)
+		read, err := io.ReadFull(d.reader, d.input[start:])
+		d.input = d.input[:start+read]
+		if err == io.EOF && len(d.input) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read exactly `n` bytes. The result aliases the input.
+func (d *Deserializer) read(n uint64) ([]byte, error) {
+	if d.reader != nil {
+		if err := d.fill(n); err != nil {
+			return nil, err
+		}
+	}
+	if n > uint64(len(d.input)-d.offset) {
+		return nil, malformedError{"unexpected end of input", ErrTruncated}
+	}
+	start := d.offset
+	d.offset += int(n)
+	return d.input[start:d.offset], nil
+}
+
+// Read the `n` bytes of a value described by `what`, e.g. "u64".
+func (d *Deserializer) readValue(n uint64, what string) ([]byte, error) {
+	start := d.offset
+	buf, err := d.read(n)
+	if err != nil {
+		return nil, d.fail(start, what, err)
+	}
+	return buf, nil
+}
+
+// Read a length-prefixed byte string. The result aliases the input.
+func (d *Deserializer) readBytes(what string) ([]byte, error) {
+	start := d.offset
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	buf, err := d.read(length)
+	if err != nil {
+		return nil, d.fail(start, what, err)
+	}
+	return buf, nil
+}
+
+func (d *Deserializer) deserializeBytes(what string) ([]byte, error) {
+	buf, err := d.readBytes(what)
+	if err != nil {
+		return nil, err
+	}
+	if d.zeroCopy {
+		return buf[:len(buf):len(buf)], nil
+	}
+	return append([]byte(nil), buf...), nil
+}
+
+func (d *Deserializer) DeserializeBytes() ([]byte, error) {
+	return d.deserializeBytes("bytes")
+}
+
+func (d *Deserializer) DeserializeStr() (string, error) {
+	buf, err := d.deserializeBytes("string")
+	return string(buf), err
+}
+
+func (d *Deserializer) DeserializeBool() (bool, error) {
+	start := d.offset
+	buf, err := d.readValue(1, "bool")
+	if err != nil {
+		return false, err
+	}
+	switch buf[0] {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		msg := fmt.Sprintf("invalid bool byte: expected 0 / 1, but got %d", buf[0])
+		return false, d.fail(start, "bool", malformedError{msg, ErrNonCanonical})
+	}
+}
+
+func (d *Deserializer) DeserializeUnit() (struct{}, error) {
+	return struct{}{}, nil
+}
+
+func (d *Deserializer) DeserializeChar() (rune, error) {
+	return 0, errors.New("BCS does not support char")
+}
+
+func (d *Deserializer) DeserializeF32() (float32, error) {
+	return 0, errors.New("BCS does not support f32")
+}
+
+func (d *Deserializer) DeserializeF64() (float64, error) {
+	return 0, errors.New("BCS does not support f64")
+}
+
+func (d *Deserializer) DeserializeU8() (uint8, error) {
+	buf, err := d.readValue(1, "u8")
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (d *Deserializer) DeserializeU16() (uint16, error) {
+	buf, err := d.readValue(2, "u16")
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(buf), nil
+}
+
+func (d *Deserializer) DeserializeU32() (uint32, error) {
+	buf, err := d.readValue(4, "u32")
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+func (d *Deserializer) DeserializeU64() (uint64, error) {
+	buf, err := d.readValue(8, "u64")
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func (d *Deserializer) DeserializeU128() (serde.Uint128, error) {
+	buf, err := d.readValue(16, "u128")
+	if err != nil {
+		return serde.Uint128{}, err
+	}
+	return serde.Uint128{High: binary.LittleEndian.Uint64(buf[8:]), Low: binary.LittleEndian.Uint64(buf)}, nil
+}
+
+func (d *Deserializer) DeserializeI8() (int8, error) {
+	buf, err := d.readValue(1, "i8")
+	if err != nil {
+		return 0, err
+	}
+	return int8(buf[0]), nil
+}
+
+func (d *Deserializer) DeserializeI16() (int16, error) {
+	buf, err := d.readValue(2, "i16")
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.LittleEndian.Uint16(buf)), nil
+}
+
+func (d *Deserializer) DeserializeI32() (int32, error) {
+	buf, err := d.readValue(4, "i32")
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(buf)), nil
+}
+
+func (d *Deserializer) DeserializeI64() (int64, error) {
+	buf, err := d.readValue(8, "i64")
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(buf)), nil
+}
+
+func (d *Deserializer) DeserializeI128() (serde.Int128, error) {
+	buf, err := d.readValue(16, "i128")
+	if err != nil {
+		return serde.Int128{}, err
+	}
+	return serde.Int128{High: int64(binary.LittleEndian.Uint64(buf[8:])), Low: binary.LittleEndian.Uint64(buf)}, nil
+}
+
+// Read a ULEB128-encoded integer fitting in 32 bits. Only the minimal encoding of a number
+// is accepted, e.g. `0x80 0x00` (a padded zero) is rejected.
+func (d *Deserializer) deserializeUleb128AsU32(what string) (uint32, error) {
+	start := d.offset
+	var value uint64
+	for shift := 0; shift < 32; shift += 7 {
+		buf, err := d.read(1)
+		if err != nil {
+			return 0, d.fail(start, what, err)
+		}
+		digit := buf[0] & 0x7f
+		value |= uint64(digit) << shift
+		if digit == buf[0] {
+			if shift > 0 && digit == 0 {
+				return 0, d.fail(start, what, malformedError{"invalid uleb128 number (unexpected zero digit)", ErrNonCanonical})
+			}
+			if value > 0xffffffff {
+				break
+			}
+			return uint32(value), nil
+		}
+	}
+	return 0, d.fail(start, what, malformedError{"overflow while parsing uleb128-encoded uint32 value", ErrMalformedInput})
+}
+
+func (d *Deserializer) DeserializeLen() (uint64, error) {
+	start := d.offset
+	length, err := d.deserializeUleb128AsU32("sequence length")
+	if err != nil {
+		return 0, err
+	}
+	if length > bcs.MaxSequenceLength {
+		return 0, d.fail(start, "sequence length", malformedError{"length is too large", ErrMalformedInput})
+	}
+	if uint64(length) > d.maxSequenceLength {
+		err := fmt.Errorf("length %d exceeds the limit of %d: %w", length, d.maxSequenceLength, ErrLimitExceeded)
+		return 0, d.fail(start, "sequence length", err)
+	}
+	// Every element of a sequence takes at least one byte in Aptos types, so a length
+	// exceeding the remaining input is necessarily invalid. The length of a stream is not
+	// known in advance, only its limit if any.
+	if d.reader == nil && uint64(length) > uint64(len(d.input)-d.offset) {
+		msg := fmt.Sprintf("length %d exceeds the remaining input", length)
+		return 0, d.fail(start, "sequence length", malformedError{msg, ErrTruncated})
+	}
+	if d.reader != nil && d.maxInputLength > 0 && uint64(length) > uint64(d.maxInputLength-d.offset) {
+		err := fmt.Errorf("length %d exceeds the input limit of %d bytes: %w", length, d.maxInputLength, ErrLimitExceeded)
+		return 0, d.fail(start, "sequence length", err)
+	}
+	return uint64(length), nil
+}
+
+func (d *Deserializer) DeserializeVariantIndex() (uint32, error) {
+	return d.deserializeUleb128AsU32("variant index")
+}
+
+func (d *Deserializer) DeserializeOptionTag() (bool, error) {
+	return d.DeserializeBool()
+}
+
+func (d *Deserializer) CheckThatKeySlicesAreIncreasing(key1, key2 serde.Slice) error {
+	if bytes.Compare(d.input[key1.Start:key1.End], d.input[key2.Start:key2.End]) >= 0 {
+		return d.fail(int(key2.Start), "map key", malformedError{"keys are not serialized in the expected order", ErrNonCanonical})
+	}
+	return nil
+}
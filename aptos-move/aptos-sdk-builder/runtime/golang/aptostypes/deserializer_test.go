@@ -0,0 +1,329 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// A `vector<vector<u8>>` claiming 2^31-1 elements, followed by two bytes.
+var hugeLengthInput = []byte{0xff, 0xff, 0xff, 0xff, 0x07, 1, 2}
+
+func TestDeserializeError(t *testing.T) {
+	// A script function call whose module name claims 200 bytes, 33 bytes in.
+	input := append([]byte{3}, make([]byte, 32)...)
+	input = append(input, 200, 'c')
+	_, err := DeserializeTransactionPayload(NewDeserializer(input, DeserializerOptions{}))
+	var e *DeserializeError
+	if !errors.As(err, &e) || e.Offset != 33 {
+		t.Fatalf("expected an error at offset 33, got %v", err)
+	}
+	if !errors.Is(err, ErrTruncated) || !errors.Is(err, ErrMalformedInput) || errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+
+	// The error of a reader is wrapped as is. The address is read byte by byte.
+	d := NewDeserializerFromReader(bytes.NewReader(input[:10]), DeserializerOptions{})
+	_, err = DeserializeTransactionPayload(d)
+	if !errors.As(err, &e) || e.Offset != 10 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected an unexpected EOF at offset 10, got %v", err)
+	}
+	if errors.Is(err, ErrMalformedInput) {
+		t.Fatalf("a failed read was reported as malformed input: %v", err)
+	}
+}
+
+func TestDeserializerLimits(t *testing.T) {
+	d := NewDeserializer(hugeLengthInput, DeserializerOptions{})
+	if _, err := DeserializeBytesVector(d); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+	d = NewDeserializerFromReader(bytes.NewReader(hugeLengthInput), DeserializerOptions{MaxInputLength: 1 << 20})
+	if _, err := DeserializeBytesVector(d); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the input limit to be exceeded, got %v", err)
+	}
+	d = NewDeserializer([]byte{3, 1, 2, 3}, DeserializerOptions{MaxSequenceLength: 2})
+	if _, err := d.DeserializeBytes(); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the sequence limit to be exceeded, got %v", err)
+	}
+
+	input := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	d = NewDeserializerFromReader(bytes.NewReader(input), DeserializerOptions{MaxInputLength: 4})
+	if _, err := d.DeserializeU64(); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the input limit to be exceeded, got %v", err)
+	}
+	d = NewDeserializerFromReader(bytes.NewReader(input), DeserializerOptions{MaxInputLength: 8})
+	if _, err := d.DeserializeU64(); err != nil {
+		t.Fatalf("a u64 should fit in 8 bytes: %v", err)
+	}
+
+	// Valid nesting beyond the depth limit is not malformed input.
+	d = NewDeserializer(nestedVectorTypeTag(3), DeserializerOptions{MaxContainerDepth: 2})
+	if _, err := DeserializeTypeTag(d); !errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrMalformedInput) {
+		t.Fatalf("expected the depth limit to be exceeded, got %v", err)
+	}
+}
+
+func TestBcsDeserializeLimits(t *testing.T) {
+	// A module bundle claiming 2^31-1 modules must be rejected before any of them is
+	// allocated.
+	var err error
+	allocs := testing.AllocsPerRun(10, func() {
+		_, err = BcsDeserializeModuleBundle(hugeLengthInput)
+	})
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+	if allocs > 10 {
+		t.Fatalf("rejecting the input took %v allocations", allocs)
+	}
+
+	defer func(options DeserializerOptions) { DefaultDeserializerOptions = options }(DefaultDeserializerOptions)
+	DefaultDeserializerOptions = DeserializerOptions{MaxSequenceLength: 2}
+	if _, err := BcsDeserializeModule([]byte{3, 1, 2, 3}); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected the sequence limit to be exceeded, got %v", err)
+	}
+	if _, err := BcsDeserializeModule([]byte{2, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nestedVectorTypeTag encodes `vector<vector<...<u8>...>>` with `depth` vectors.
+func nestedVectorTypeTag(depth int) []byte {
+	return append(bytes.Repeat([]byte{6}, depth), 1)
+}
+
+// nestedVectorPayload encodes a call of `0x0::coin::transfer` with a type argument of
+// `depth` nested vectors.
+func nestedVectorPayload(depth int) []byte {
+	payload := append([]byte{3}, make([]byte, 32)...)
+	payload = append(payload, 4, 'c', 'o', 'i', 'n', 8, 't', 'r', 'a', 'n', 's', 'f', 'e', 'r', 1)
+	payload = append(payload, nestedVectorTypeTag(depth)...)
+	return append(payload, 0)
+}
+
+func isDepthExceeded(err error) bool {
+	return errors.Is(err, ErrLimitExceeded) && strings.Contains(err.Error(), "max container depth")
+}
+
+func TestDeserializerDepth(t *testing.T) {
+	if _, err := BcsDeserializeTypeTag(nestedVectorTypeTag(600)); !isDepthExceeded(err) {
+		t.Fatalf("expected the container depth to be exceeded, got %v", err)
+	}
+	if _, err := BcsDeserializeTransactionPayload(nestedVectorPayload(10)); err != nil {
+		t.Fatalf("failed to decode 10 nested vectors: %v", err)
+	}
+	if _, err := BcsDeserializeTransactionPayload(nestedVectorPayload(600)); !isDepthExceeded(err) {
+		t.Fatalf("expected the container depth to be exceeded, got %v", err)
+	}
+
+	d := NewDeserializer(nestedVectorTypeTag(10), DeserializerOptions{MaxContainerDepth: 5})
+	if _, err := DeserializeTypeTag(d); !isDepthExceeded(err) {
+		t.Fatalf("expected the container depth to be exceeded, got %v", err)
+	}
+	defer func(options DeserializerOptions) { DefaultDeserializerOptions = options }(DefaultDeserializerOptions)
+	DefaultDeserializerOptions = DeserializerOptions{MaxContainerDepth: 5}
+	if _, err := BcsDeserializeTypeTag(nestedVectorTypeTag(10)); !isDepthExceeded(err) {
+		t.Fatalf("expected the container depth to be exceeded, got %v", err)
+	}
+}
+
+func TestDeserializerUleb128(t *testing.T) {
+	for _, test := range []struct {
+		input []byte
+		value uint32
+		err   error
+	}{
+		{[]byte{0x00}, 0, nil},
+		{[]byte{0x80, 0x00}, 0, ErrNonCanonical},
+		{[]byte{0x7f}, 127, nil},
+		{[]byte{0xff, 0x00}, 0, ErrNonCanonical},
+		{[]byte{0x80, 0x01}, 128, nil},
+		{[]byte{0x80, 0x81, 0x00}, 0, ErrNonCanonical},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0x0f}, 0xffffffff, nil},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0x10}, 0, ErrMalformedInput},
+		{[]byte{0x80}, 0, ErrTruncated},
+	} {
+		d := NewDeserializer(test.input, DeserializerOptions{})
+		value, err := d.DeserializeVariantIndex()
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("%x: expected %v, got %v", test.input, test.err, err)
+			}
+			continue
+		}
+		if err != nil || value != test.value || d.Remaining() != 0 {
+			t.Errorf("%x: expected %d, got %d, %v", test.input, test.value, value, err)
+		}
+	}
+
+	if module, err := BcsDeserializeModule([]byte{0x00}); err != nil || len(module.Code) != 0 {
+		t.Fatalf("failed to decode an empty module: %v", err)
+	}
+	if _, err := BcsDeserializeModule([]byte{0x80, 0x00}); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected a non-canonical length, got %v", err)
+	}
+	if _, err := BcsDeserializeModule([]byte{0x05, 1, 2}); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+}
+
+func TestDeserializerBoolAndUnit(t *testing.T) {
+	for input, want := range map[byte]bool{0: false, 1: true} {
+		if value, err := NewDeserializer([]byte{input}, DeserializerOptions{}).DeserializeBool(); err != nil || value != want {
+			t.Errorf("%d: expected %t, got %t, %v", input, want, value, err)
+		}
+	}
+	if _, err := NewDeserializer([]byte{2}, DeserializerOptions{}).DeserializeBool(); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected the bool byte 2 to be rejected, got %v", err)
+	}
+	// A bool argument of a script.
+	if _, err := BcsDeserializeTransactionArgument([]byte{5, 2}); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected the bool byte 2 to be rejected, got %v", err)
+	}
+
+	d := NewDeserializer([]byte{0xff}, DeserializerOptions{})
+	if _, err := d.DeserializeUnit(); err != nil || d.Offset() != 0 {
+		t.Fatalf("decoding a unit consumed %d bytes: %v", d.Offset(), err)
+	}
+	// Unit variants are encoded as their variant index alone.
+	tag := TypeTag(&TypeTag__Bool{})
+	input, err := tag.BcsSerialize()
+	if err != nil || !bytes.Equal(input, []byte{0}) {
+		t.Fatalf("unexpected encoding %x: %v", input, err)
+	}
+	d = NewDeserializer(append(input, 0xff), DeserializerOptions{})
+	output, err := DeserializeTypeTag(d)
+	if _, ok := output.(*TypeTag__Bool); !ok || err != nil || d.Offset() != 1 {
+		t.Fatalf("failed to decode a unit variant: %v", err)
+	}
+}
+
+func TestDeserializeUnknownVariant(t *testing.T) {
+	_, err := BcsDeserializeTypeTag([]byte{99})
+	if !errors.Is(err, ErrUnknownVariant) || !strings.Contains(err.Error(), "99") {
+		t.Fatalf("expected an unknown variant 99, got %v", err)
+	}
+	// Variant indices are decoded before they are checked, so a multi-byte index is reported
+	// whole.
+	_, err = BcsDeserializeTransactionPayload([]byte{0x80, 0x01})
+	if !errors.Is(err, ErrUnknownVariant) || !strings.Contains(err.Error(), "128") {
+		t.Fatalf("expected an unknown variant 128, got %v", err)
+	}
+}
+
+func TestDeserializerRemainingBytes(t *testing.T) {
+	first, err := coinTransferPayload().BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := (&TransactionPayload__Script{Value: Script{Code: []byte{1, 2, 3}}}).BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := append(append([]byte(nil), first...), second...)
+
+	d := NewDeserializer(input, DeserializerOptions{})
+	if _, err := DeserializeTransactionPayload(d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Offset() != len(first) || d.Remaining() != len(second) || !bytes.Equal(d.RemainingBytes(), second) {
+		t.Fatalf("stopped at %d with %d bytes left, expected %d and %d", d.Offset(), d.Remaining(), len(first), len(second))
+	}
+	payload, err := BcsDeserializeTransactionPayload(d.RemainingBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script, ok := payload.(*TransactionPayload__Script); !ok || !bytes.Equal(script.Value.Code, []byte{1, 2, 3}) {
+		t.Fatalf("unexpected second payload %#v", payload)
+	}
+	if _, err := DeserializeTransactionPayload(d); err != nil || d.Remaining() != 0 || len(d.RemainingBytes()) != 0 {
+		t.Fatalf("failed to decode the second payload in place: %v", err)
+	}
+
+	// The whole input is rejected by the one-shot decoder.
+	if _, err := BcsDeserializeTransactionPayload(input); err == nil {
+		t.Fatal("accepted trailing bytes")
+	}
+}
+
+func TestDeserializerSkip(t *testing.T) {
+	raw := RawTransaction{Sender: AccountAddress{5}, SequenceNumber: 42, Payload: &TransactionPayload__ScriptFunction{}}
+	input, err := raw.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Skip the sender to read the sequence number.
+	d := NewDeserializer(input, DeserializerOptions{})
+	if err := d.Skip(AccountAddressLength); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.DeserializeU64(); err != nil || n != 42 || d.Remaining() != len(input)-40 {
+		t.Fatalf("expected the sequence number 42, got %d, %v", n, err)
+	}
+	if err := d.Skip(len(input)); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+	if err := d.Skip(-1); err == nil {
+		t.Fatal("skipped a negative number of bytes")
+	}
+
+	d = NewDeserializerFromReader(bytes.NewReader(input), DeserializerOptions{})
+	if err := d.Skip(AccountAddressLength); err != nil || d.Remaining() != 0 || d.Offset() != AccountAddressLength {
+		t.Fatalf("failed to skip the sender of a stream: %v", err)
+	}
+	if n, err := d.DeserializeU64(); err != nil || n != 42 {
+		t.Fatalf("expected the sequence number 42, got %d, %v", n, err)
+	}
+	if err := d.Skip(len(input)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected an unexpected EOF, got %v", err)
+	}
+}
+
+func TestDeserializerZeroCopy(t *testing.T) {
+	script := Script{Code: []byte{1, 2, 3}, Args: []TransactionArgument{&TransactionArgument__U8Vector{4, 5}}}
+	input, err := script.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DeserializeScript(NewDeserializer(input, DeserializerOptions{ZeroCopy: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The code aliases the input, after its length byte.
+	input[1] = 9
+	if decoded.Code[0] != 9 || cap(decoded.Code) != len(decoded.Code) {
+		t.Fatalf("the code %x does not alias the input", decoded.Code)
+	}
+	// Appending reallocates rather than overwriting the next field.
+	decoded.Code = append(decoded.Code, 7)
+	if input[4] != 0 {
+		t.Fatalf("appending to the code overwrote the input %x", input)
+	}
+
+	decoded, err = DeserializeScript(NewDeserializer(input, DeserializerOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input[1] = 8
+	if decoded.Code[0] != 9 || !bytes.Equal(*decoded.Args[0].(*TransactionArgument__U8Vector), []byte{4, 5}) {
+		t.Fatalf("the code %x aliases the input", decoded.Code)
+	}
+
+	// One allocation less per byte vector.
+	zeroCopy := testing.AllocsPerRun(100, func() {
+		_, _ = DeserializeScript(NewDeserializer(input, DeserializerOptions{ZeroCopy: true}))
+	})
+	copying := testing.AllocsPerRun(100, func() {
+		_, _ = DeserializeScript(NewDeserializer(input, DeserializerOptions{}))
+	})
+	if copying-zeroCopy != 2 {
+		t.Fatalf("expected 2 allocations less with ZeroCopy, got %v and %v", zeroCopy, copying)
+	}
+}
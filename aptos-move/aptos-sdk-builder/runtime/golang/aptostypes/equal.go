@@ -0,0 +1,132 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "bytes"
+
+// The `Equal` methods below compare payloads field by field. Unlike `reflect.DeepEqual`,
+// they treat nil and empty slices as equal, so that two payloads are equal exactly when
+// their BCS encodings are.
+
+// EqualTransactionPayload reports whether the two payloads are the same variant with
+// equal fields. Write-set payloads are compared by their BCS encodings.
+func EqualTransactionPayload(a, b TransactionPayload) bool {
+	switch a := a.(type) {
+	case *TransactionPayload__WriteSet:
+		b, ok := b.(*TransactionPayload__WriteSet)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		encodedA, errA := a.BcsSerialize()
+		encodedB, errB := b.BcsSerialize()
+		return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+	case *TransactionPayload__Script:
+		b, ok := b.(*TransactionPayload__Script)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		return a.Value.Equal(&b.Value)
+	case *TransactionPayload__ModuleBundle:
+		b, ok := b.(*TransactionPayload__ModuleBundle)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		return a.Value.Equal(&b.Value)
+	case *TransactionPayload__ScriptFunction:
+		b, ok := b.(*TransactionPayload__ScriptFunction)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		return a.Value.Equal(&b.Value)
+	}
+	return a == nil && b == nil
+}
+
+func (obj *TransactionPayload__WriteSet) Equal(other TransactionPayload) bool {
+	return EqualTransactionPayload(obj, other)
+}
+
+func (obj *TransactionPayload__Script) Equal(other TransactionPayload) bool {
+	return EqualTransactionPayload(obj, other)
+}
+
+func (obj *TransactionPayload__ModuleBundle) Equal(other TransactionPayload) bool {
+	return EqualTransactionPayload(obj, other)
+}
+
+func (obj *TransactionPayload__ScriptFunction) Equal(other TransactionPayload) bool {
+	return EqualTransactionPayload(obj, other)
+}
+
+// Equal reports whether the two script functions call the same function with the same type
+// arguments and encoded arguments.
+func (obj *ScriptFunction) Equal(other *ScriptFunction) bool {
+	if obj == nil || other == nil {
+		return obj == other
+	}
+	return obj.Module == other.Module &&
+		obj.Function == other.Function &&
+		equalTypeTags(obj.TyArgs, other.TyArgs) &&
+		EqualBytesVector(obj.Args, other.Args)
+}
+
+// Equal reports whether the two scripts have the same bytecode and arguments.
+func (obj *Script) Equal(other *Script) bool {
+	if obj == nil || other == nil {
+		return obj == other
+	}
+	if !bytes.Equal(obj.Code, other.Code) ||
+		!equalTypeTags(obj.TyArgs, other.TyArgs) ||
+		len(obj.Args) != len(other.Args) {
+		return false
+	}
+	for i := range obj.Args {
+		if !EqualTransactionArgument(obj.Args[i], other.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether the two bundles hold the same modules, in the same order.
+func (obj *ModuleBundle) Equal(other *ModuleBundle) bool {
+	if obj == nil || other == nil {
+		return obj == other
+	}
+	if len(obj.Codes) != len(other.Codes) {
+		return false
+	}
+	for i := range obj.Codes {
+		if !bytes.Equal(obj.Codes[i].Code, other.Codes[i].Code) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualTransactionArgument reports whether the two script arguments are the same variant
+// with equal values.
+func EqualTransactionArgument(a, b TransactionArgument) bool {
+	switch a := a.(type) {
+	case *TransactionArgument__U8:
+		b, ok := b.(*TransactionArgument__U8)
+		return ok && (a == b || a != nil && b != nil && *a == *b)
+	case *TransactionArgument__U64:
+		b, ok := b.(*TransactionArgument__U64)
+		return ok && (a == b || a != nil && b != nil && *a == *b)
+	case *TransactionArgument__U128:
+		b, ok := b.(*TransactionArgument__U128)
+		return ok && (a == b || a != nil && b != nil && *a == *b)
+	case *TransactionArgument__Address:
+		b, ok := b.(*TransactionArgument__Address)
+		return ok && (a == b || a != nil && b != nil && *a == *b)
+	case *TransactionArgument__U8Vector:
+		b, ok := b.(*TransactionArgument__U8Vector)
+		return ok && (a == b || a != nil && b != nil && bytes.Equal(*a, *b))
+	case *TransactionArgument__Bool:
+		b, ok := b.(*TransactionArgument__Bool)
+		return ok && (a == b || a != nil && b != nil && *a == *b)
+	}
+	return a == nil && b == nil
+}
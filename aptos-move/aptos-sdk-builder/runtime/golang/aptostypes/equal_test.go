@@ -0,0 +1,91 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEqualTransactionPayload(t *testing.T) {
+	module := ModuleId{Address: CoreCodeAddress, Name: "coin"}
+	a := &TransactionPayload__ScriptFunction{Value: ScriptFunction{Module: module, Function: "transfer"}}
+	b := &TransactionPayload__ScriptFunction{Value: ScriptFunction{Module: module, Function: "transfer", TyArgs: []TypeTag{}, Args: [][]byte{}}}
+	// Nil and empty slices have the same encoding, which `reflect.DeepEqual` ignores.
+	encodedA, errA := a.BcsSerialize()
+	encodedB, errB := b.BcsSerialize()
+	if errA != nil || errB != nil || !bytes.Equal(encodedA, encodedB) {
+		t.Fatalf("unexpected encodings %x and %x", encodedA, encodedB)
+	}
+	if reflect.DeepEqual(a, b) {
+		t.Fatal("reflect.DeepEqual no longer tells nil and empty slices apart")
+	}
+	if !EqualTransactionPayload(a, b) || !b.Equal(a) {
+		t.Fatal("payloads with the same encoding are not equal")
+	}
+
+	for name, other := range map[string]TransactionPayload{
+		"function":      &TransactionPayload__ScriptFunction{Value: ScriptFunction{Module: module, Function: "register"}},
+		"type argument": &TransactionPayload__ScriptFunction{Value: ScriptFunction{Module: module, Function: "transfer", TyArgs: []TypeTag{U8TypeTag}}},
+		"argument":      &TransactionPayload__ScriptFunction{Value: ScriptFunction{Module: module, Function: "transfer", Args: [][]byte{{}}}},
+		"variant":       &TransactionPayload__Script{},
+		"typed nil":     (*TransactionPayload__ScriptFunction)(nil),
+		"nil":           nil,
+	} {
+		if EqualTransactionPayload(a, other) || EqualTransactionPayload(other, a) {
+			t.Errorf("a payload with a different %s is equal", name)
+		}
+	}
+	if !EqualTransactionPayload(nil, nil) || !EqualTransactionPayload((*TransactionPayload__Script)(nil), (*TransactionPayload__Script)(nil)) {
+		t.Fatal("nil payloads are not equal")
+	}
+
+	bundle := &TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{Code: []byte{1}}, {}}}}
+	if !bundle.Equal(&TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{Code: []byte{1}}, {Code: []byte{}}}}}) {
+		t.Fatal("bundles with the same encoding are not equal")
+	}
+	if bundle.Equal(&TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{Code: []byte{1}}}}}) {
+		t.Fatal("bundles with different modules are equal")
+	}
+}
+
+func TestScriptEqual(t *testing.T) {
+	u8 := TransactionArgument__U8(1)
+	a := &Script{Args: []TransactionArgument{&u8, &TransactionArgument__U8Vector{}}}
+	empty := TransactionArgument__U8Vector(nil)
+	b := &Script{Code: []byte{}, TyArgs: []TypeTag{}, Args: []TransactionArgument{&u8, &empty}}
+	if !a.Equal(b) || reflect.DeepEqual(a, b) {
+		t.Fatal("scripts with the same encoding are not equal")
+	}
+	other := TransactionArgument__U8(2)
+	if a.Equal(&Script{Args: []TransactionArgument{&other, &empty}}) {
+		t.Fatal("scripts with different arguments are equal")
+	}
+	if a.Equal(&Script{Code: []byte{1}, Args: a.Args}) || a.Equal(nil) || !(*Script)(nil).Equal(nil) {
+		t.Fatal("unexpected equality")
+	}
+}
+
+func TestEqualTransactionArgument(t *testing.T) {
+	one, otherOne := TransactionArgument__U64(1), TransactionArgument__U64(1)
+	two := TransactionArgument__U64(2)
+	u8 := TransactionArgument__U8(1)
+	for _, test := range []struct {
+		a, b TransactionArgument
+		want bool
+	}{
+		{&one, &otherOne, true},
+		{&one, &two, false},
+		{&one, &u8, false},
+		{&one, nil, false},
+		{(*TransactionArgument__U64)(nil), (*TransactionArgument__U64)(nil), true},
+		{(*TransactionArgument__U64)(nil), &one, false},
+		{nil, nil, true},
+	} {
+		if got := EqualTransactionArgument(test.a, test.b); got != test.want {
+			t.Errorf("%#v and %#v: expected %t, got %t", test.a, test.b, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"fmt"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// SerializeFixedBytes writes exactly `n` bytes without a length prefix, which is how BCS
+// encodes a Rust array `[u8; N]`. It fails if `b` does not have length `n`.
+//
+// Note that Ed25519 keys and signatures are serialized as length-prefixed byte vectors
+// in Aptos transactions, so the generated authenticator types do not use these helpers.
+func SerializeFixedBytes(serializer serde.Serializer, b []byte, n int) error {
+	if len(b) != n {
+		return fmt.Errorf("invalid fixed-size byte array: expected %d bytes, got %d", n, len(b))
+	}
+	for _, value := range b {
+		if err := serializer.SerializeU8(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeserializeFixedBytes reads exactly `n` bytes written by `SerializeFixedBytes`.
+func DeserializeFixedBytes(deserializer serde.Deserializer, n int) ([]byte, error) {
+	b := make([]byte, 0, n)
+	for i := 0; i < n; i++ {
+		value, err := deserializer.DeserializeU8()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, value)
+	}
+	return b, nil
+}
+
+// Bitmap4 is a 32-bit bitmap encoded as the Rust array `[u8; 4]`, e.g. the signer bitmap of
+// a `MultiEd25519Signature`. Its 4 bytes are serialized verbatim, unlike a `vector<u8>`.
+// Bit 0 is the most significant bit of the first byte, as in the framework.
+type Bitmap4 [4]uint8
+
+// Set sets bit `i`. It panics if `i` is not smaller than 32.
+func (obj *Bitmap4) Set(i uint8) {
+	obj[i/8] |= 128 >> (i % 8)
+}
+
+// IsSet reports whether bit `i` is set. It panics if `i` is not smaller than 32.
+func (obj Bitmap4) IsSet(i uint8) bool {
+	return obj[i/8]&(128>>(i%8)) != 0
+}
+
+func (obj *Bitmap4) Serialize(serializer serde.Serializer) error {
+	return SerializeFixedBytes(serializer, obj[:], len(obj))
+}
+
+func (obj *Bitmap4) BcsSerialize() ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot serialize null object")
+	}
+	serializer := bcs.NewSerializer()
+	if err := obj.Serialize(serializer); err != nil {
+		return nil, err
+	}
+	return serializer.GetBytes(), nil
+}
+
+func DeserializeBitmap4(deserializer serde.Deserializer) (Bitmap4, error) {
+	var obj Bitmap4
+	b, err := DeserializeFixedBytes(deserializer, len(obj))
+	if err != nil {
+		return obj, err
+	}
+	copy(obj[:], b)
+	return obj, nil
+}
+
+func BcsDeserializeBitmap4(input []byte) (Bitmap4, error) {
+	deserializer := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeBitmap4(deserializer)
+	if err == nil && deserializer.GetBufferOffset() < uint64(len(input)) {
+		return obj, fmt.Errorf("some input bytes were not read")
+	}
+	return obj, err
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFixedBytes(t *testing.T) {
+	serializer := NewSerializerWithBuffer(nil)
+	if err := SerializeFixedBytes(serializer, []byte{1, 2, 3}, 3); err != nil {
+		t.Fatal(err)
+	}
+	// No length prefix.
+	if !bytes.Equal(serializer.GetBytes(), []byte{1, 2, 3}) {
+		t.Fatalf("unexpected encoding %x", serializer.GetBytes())
+	}
+	if err := SerializeFixedBytes(serializer, []byte{1, 2}, 3); err == nil {
+		t.Fatal("serialized 2 bytes as a 3-byte array")
+	}
+	if b, err := DeserializeFixedBytes(NewDeserializer([]byte{1, 2, 3, 4}, DeserializerOptions{}), 3); err != nil || !bytes.Equal(b, []byte{1, 2, 3}) {
+		t.Fatalf("unexpected bytes %x: %v", b, err)
+	}
+	if _, err := DeserializeFixedBytes(NewDeserializer([]byte{1, 2}, DeserializerOptions{}), 3); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+}
+
+func TestBitmap4(t *testing.T) {
+	var bitmap Bitmap4
+	for _, i := range []uint8{0, 9, 31} {
+		bitmap.Set(i)
+	}
+	// Bit 0 is the most significant bit of the first byte.
+	if bitmap != (Bitmap4{0x80, 0x40, 0x00, 0x01}) {
+		t.Fatalf("unexpected bitmap %x", bitmap)
+	}
+	for i := uint8(0); i < 32; i++ {
+		if set := i == 0 || i == 9 || i == 31; bitmap.IsSet(i) != set {
+			t.Errorf("bit %d: expected %t", i, set)
+		}
+	}
+
+	// The 4 bytes, verbatim.
+	encoded, err := bitmap.BcsSerialize()
+	if err != nil || !bytes.Equal(encoded, bitmap[:]) {
+		t.Fatalf("unexpected encoding %x: %v", encoded, err)
+	}
+	if decoded, err := BcsDeserializeBitmap4(encoded); err != nil || decoded != bitmap {
+		t.Fatalf("unexpected bitmap %x: %v", decoded, err)
+	}
+	if _, err := BcsDeserializeBitmap4(encoded[:3]); err == nil {
+		t.Fatal("accepted a 3-byte bitmap")
+	}
+	if _, err := BcsDeserializeBitmap4(append(encoded, 0)); err == nil {
+		t.Fatal("accepted a trailing byte")
+	}
+}
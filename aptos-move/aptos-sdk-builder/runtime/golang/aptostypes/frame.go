@@ -0,0 +1,58 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WriteFrame writes `payload` prefixed with its ULEB128-encoded length, i.e. the BCS
+// encoding of a byte vector. Frames can be read back one at a time with `ReadFrame`.
+func WriteFrame(w io.Writer, payload []byte) error {
+	s := NewSerializerWithBuffer(make([]byte, 0, 5+len(payload)))
+	if err := s.SerializeBytes(payload); err != nil {
+		return err
+	}
+	_, err := w.Write(s.GetBytes())
+	return err
+}
+
+// ReadFrame reads one frame written by `WriteFrame` and returns its payload without the
+// length prefix. It returns `io.EOF` if `r` ends between two frames, and
+// `io.ErrUnexpectedEOF` if it ends in the middle of a frame.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	payload, err := NewDeserializerFromReader(r, DefaultDeserializerOptions).DeserializeBytes()
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return payload, err
+}
+
+// ScanTransactions reads frames written by `WriteFrame` from `r` until it ends, each holding
+// the BCS encoding of a `SignedTransaction`, e.g. from a dump of a block, and calls `fn` with
+// the index of each frame and either the decoded transaction or the decoding error. Malformed
+// transactions do not end the scan: it stops early only if `fn` returns false. Errors of the
+// framing itself, e.g. a truncated last frame, end the scan and are returned, since the
+// following frames cannot be located.
+func ScanTransactions(r io.Reader, fn func(idx int, tx *SignedTransaction, err error) bool) error {
+	for idx := 0; ; idx++ {
+		payload, err := ReadFrame(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", idx, err)
+		}
+		var tx *SignedTransaction
+		decoded, err := BcsDeserializeSignedTransaction(payload)
+		if err == nil {
+			tx = &decoded
+		}
+		if !fn(idx, tx, err) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestReadFrame(t *testing.T) {
+	var stream bytes.Buffer
+	// A length of 300 takes two uleb128 bytes.
+	payloads := [][]byte{{1, 2, 3}, bytes.Repeat([]byte{7}, 300), {}}
+	for _, payload := range payloads {
+		if err := WriteFrame(&stream, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := append([]byte(nil), stream.Bytes()...)
+	if !bytes.Equal(input[:4], []byte{3, 1, 2, 3}) || !bytes.Equal(input[4:6], []byte{0xac, 0x02}) {
+		t.Fatalf("unexpected frames %x", input[:6])
+	}
+	for _, want := range payloads {
+		if payload, err := ReadFrame(&stream); err != nil || !bytes.Equal(payload, want) {
+			t.Fatalf("expected %d bytes, got %d, %v", len(want), len(payload), err)
+		}
+	}
+	if _, err := ReadFrame(&stream); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	// A stream ending in the middle of the second frame.
+	r := bytes.NewReader(input[:10])
+	if _, err := ReadFrame(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFrame(r); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestScanTransactions(t *testing.T) {
+	privKey, _ := testKeyPair([32]byte{1})
+	raw := RawTransaction{SequenceNumber: 1, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	signed, err := raw.SignEd25519(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := signed.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dump bytes.Buffer
+	for _, frame := range [][]byte{valid, valid[:len(valid)-1], valid, append(valid, 0)} {
+		if err := WriteFrame(&dump, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var events []string
+	scan := func(r io.Reader, limit int) error {
+		events = nil
+		return ScanTransactions(r, func(idx int, tx *SignedTransaction, err error) bool {
+			events = append(events, fmt.Sprintf("%d:%t:%t", idx, tx != nil, err != nil))
+			return len(events) < limit
+		})
+	}
+	if err := scan(bytes.NewReader(dump.Bytes()), 10); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(events) != "[0:true:false 1:false:true 2:true:false 3:false:true]" {
+		t.Fatalf("unexpected callbacks %v", events)
+	}
+	if err := scan(bytes.NewReader(dump.Bytes()), 2); err != nil || len(events) != 2 {
+		t.Fatalf("the scan did not stop: %v, %v", events, err)
+	}
+	truncated := dump.Bytes()[:dump.Len()-1]
+	if err := scan(bytes.NewReader(truncated), 10); !errors.Is(err, io.ErrUnexpectedEOF) || len(events) != 3 {
+		t.Fatalf("unexpected result for a truncated dump: %v, %v", events, err)
+	}
+}
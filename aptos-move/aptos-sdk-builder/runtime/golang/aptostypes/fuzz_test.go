@@ -0,0 +1,74 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Decoding then re-encoding a valid transaction payload must give back the same bytes,
+// i.e. the decoder only accepts canonical BCS encodings.
+//
+//	go test -fuzz FuzzTransactionPayloadRoundTrip
+func FuzzTransactionPayloadRoundTrip(f *testing.F) {
+	coin, err := ParseTypeTag("0x1::aptos_coin::AptosCoin")
+	if err != nil {
+		f.Fatal(err)
+	}
+	receiver, err := ParseAccountAddress("0x1234")
+	if err != nil {
+		f.Fatal(err)
+	}
+	amount := []byte{0x40, 0x42, 0x0f, 0, 0, 0, 0, 0}
+	seeds := []TransactionPayload{
+		&TransactionPayload__ScriptFunction{Value: ScriptFunction{
+			Module:   ModuleId{Address: AccountAddress{31: 1}, Name: "coin"},
+			Function: "transfer",
+			TyArgs:   []TypeTag{coin},
+			Args:     [][]byte{receiver[:], amount},
+		}},
+		&TransactionPayload__ScriptFunction{Value: ScriptFunction{
+			Module:   ModuleId{Address: AccountAddress{31: 1}, Name: "account"},
+			Function: "create_account",
+			Args:     [][]byte{receiver[:]},
+		}},
+		&TransactionPayload__Script{Value: Script{
+			Code:   []byte{0xa1, 0x1c, 0xeb, 0x0b},
+			TyArgs: []TypeTag{&TypeTag__Vector{Value: coin}},
+			Args: []TransactionArgument{
+				&TransactionArgument__Address{Value: receiver},
+				(*TransactionArgument__U64)(new(uint64)),
+				(*TransactionArgument__Bool)(new(bool)),
+			},
+		}},
+		&TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{Code: []byte{0xa1, 0x1c, 0xeb, 0x0b}}}}},
+	}
+	for _, seed := range seeds {
+		input, err := seed.BcsSerialize()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(input)
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		// Unlike `bcs.NewDeserializer`, `Deserializer` rejects lengths exceeding the input
+		// instead of allocating them, which would exhaust the memory of the fuzzer.
+		d := NewDeserializer(input, DeserializerOptions{})
+		payload, err := DeserializeTransactionPayload(d)
+		if err != nil || d.GetBufferOffset() != uint64(len(input)) {
+			return
+		}
+		output, err := payload.BcsSerialize()
+		if err != nil {
+			t.Fatalf("failed to serialize a decoded payload: %v", err)
+		}
+		if !bytes.Equal(input, output) {
+			t.Fatalf("non-canonical input was accepted:\n  input:  %x\n  output: %x", input, output)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ToHex returns "0x" followed by the lowercase hex encoding of `b`, e.g. to pass
+// serialized bytes to the CLI or to `curl`.
+func ToHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// FromHex decodes a hex string written by `ToHex`. The "0x" prefix is optional and both
+// cases are accepted.
+func FromHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex string: %w", err)
+	}
+	return b, nil
+}
+
+// Number of bytes shown by `BcsDiff` on each side of the first difference.
+const diffContext = 8
+
+// BcsDiff describes where two encodings first differ, e.g. the BCS bytes of a transaction
+// built here and of the same transaction built by another SDK, with the bytes around that
+// offset in hex, the first differing byte in brackets. It returns the empty string if the
+// encodings are equal.
+func BcsDiff(a, b []byte) string {
+	offset := 0
+	for offset < len(a) && offset < len(b) && a[offset] == b[offset] {
+		offset++
+	}
+	if offset == len(a) && offset == len(b) {
+		return ""
+	}
+	return fmt.Sprintf("bytes differ at offset %d (lengths %d and %d):\n  %s\n  %s",
+		offset, len(a), len(b), diffWindow(a, offset), diffWindow(b, offset))
+}
+
+// Show the bytes of `b` around `offset`, which may be the end of `b`.
+func diffWindow(b []byte, offset int) string {
+	var w strings.Builder
+	start := offset - diffContext
+	if start > 0 {
+		w.WriteString("...")
+	} else {
+		start = 0
+	}
+	w.WriteString(hex.EncodeToString(b[start:offset]))
+	if offset == len(b) {
+		w.WriteString("[end]")
+		return w.String()
+	}
+	fmt.Fprintf(&w, "[%02x]", b[offset])
+	end := offset + 1 + diffContext
+	if end >= len(b) {
+		end = len(b)
+	}
+	w.WriteString(hex.EncodeToString(b[offset+1 : end]))
+	if end < len(b) {
+		w.WriteString("...")
+	}
+	return w.String()
+}
+
+// The `Hex` methods below return the `ToHex` form of the BCS encoding.
+
+func (obj *SignedTransaction) Hex() (string, error) {
+	return bcsHex(obj.BcsSerialize())
+}
+
+func (obj *TransactionPayload__WriteSet) Hex() (string, error) {
+	return bcsHex(obj.BcsSerialize())
+}
+
+func (obj *TransactionPayload__Script) Hex() (string, error) {
+	return bcsHex(obj.BcsSerialize())
+}
+
+func (obj *TransactionPayload__ModuleBundle) Hex() (string, error) {
+	return bcsHex(obj.BcsSerialize())
+}
+
+func (obj *TransactionPayload__ScriptFunction) Hex() (string, error) {
+	return bcsHex(obj.BcsSerialize())
+}
+
+func bcsHex(b []byte, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return ToHex(b), nil
+}
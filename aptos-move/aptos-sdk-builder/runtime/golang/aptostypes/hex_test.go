@@ -0,0 +1,36 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBcsDiff(t *testing.T) {
+	txn := RawTransaction{SequenceNumber: 7, MaxGasAmount: 2000, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	a, err := txn.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn.MaxGasAmount = 3000
+	b, err := txn.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := BcsDiff(a, a); diff != "" {
+		t.Fatalf("unexpected diff for equal bytes: %s", diff)
+	}
+
+	// The sender and the sequence number take 40 bytes, then comes the payload, whose variant
+	// index and empty script function take 37 bytes, then the maximal gas amount.
+	diff := BcsDiff(a, b)
+	if !strings.HasPrefix(diff, "bytes differ at offset 77 ") ||
+		!strings.Contains(diff, "...0000000000000000[d0]0700000000000000...") {
+		t.Fatalf("unexpected diff: %s", diff)
+	}
+	if diff := BcsDiff(a, a[:10]); !strings.HasPrefix(diff, "bytes differ at offset 10 ") || !strings.HasSuffix(diff, "[end]") {
+		t.Fatalf("unexpected diff: %s", diff)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// MaxIdentifierLength is the largest identifier length accepted by the Move binary format.
+const MaxIdentifierLength = 65535
+
+// NewIdentifier checks that `s` is a valid Move identifier.
+func NewIdentifier(s string) (Identifier, error) {
+	obj := Identifier(s)
+	if err := obj.Validate(); err != nil {
+		return "", err
+	}
+	return obj, nil
+}
+
+// Validate checks that the identifier is non-empty, starts with a letter or an underscore,
+// continues with letters, digits or underscores, and is at most `MaxIdentifierLength` long.
+func (obj Identifier) Validate() error {
+	if len(obj) > MaxIdentifierLength {
+		return fmt.Errorf("invalid identifier: length %d exceeds %d", len(obj), MaxIdentifierLength)
+	}
+	if !isValidIdentifier(string(obj)) {
+		return fmt.Errorf("invalid identifier %q", string(obj))
+	}
+	return nil
+}
+
+// Validate checks the module name of the module ID.
+func (obj *ModuleId) Validate() error {
+	return obj.Name.Validate()
+}
+
+// Validate checks the identifiers of the struct type and of its type arguments.
+func (obj *StructTag) Validate() error {
+	if err := obj.Module.Validate(); err != nil {
+		return err
+	}
+	if err := obj.Name.Validate(); err != nil {
+		return err
+	}
+	for _, arg := range obj.TypeArgs {
+		if err := ValidateTypeTag(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTypeTag checks that `tag` and the types nested in it are set, and checks the
+// identifiers of all the struct types occurring in `tag`.
+//
+// The number of type arguments of a generic struct is not known here, since it is not part
+// of the ABIs. It is checked on-chain.
+func ValidateTypeTag(tag TypeTag) error {
+	switch tag := tag.(type) {
+	case nil:
+		return errors.New("missing type tag")
+	case *TypeTag__Vector:
+		if tag == nil {
+			return errors.New("missing type tag")
+		}
+		return ValidateTypeTag(tag.Value)
+	case *TypeTag__Struct:
+		if tag == nil {
+			return errors.New("missing type tag")
+		}
+		return tag.Value.Validate()
+	default:
+		return nil
+	}
+}
+
+// BcsSerializeTypeTags validates the type tags, as `ValidateTypeTag` does, then returns the
+// BCS encoding of the vector they make, e.g. the type arguments of a script function call.
+// Validation errors give the index of the first invalid tag.
+func BcsSerializeTypeTags(tags []TypeTag) ([]byte, error) {
+	for i, tag := range tags {
+		if err := ValidateTypeTag(tag); err != nil {
+			return nil, fmt.Errorf("type argument %d: %w", i, err)
+		}
+	}
+	return bcsSerializeVector(len(tags), func(serializer serde.Serializer, i int) error {
+		return tags[i].Serialize(serializer)
+	})
+}
+
+// Validate checks the module, function name and type arguments of the script function call,
+// and that no argument is empty, since the BCS encoding of a Move value takes at least one
+// byte.
+func (obj *ScriptFunction) Validate() error {
+	if err := obj.Module.Validate(); err != nil {
+		return err
+	}
+	if err := obj.Function.Validate(); err != nil {
+		return err
+	}
+	for _, arg := range obj.TyArgs {
+		if err := ValidateTypeTag(arg); err != nil {
+			return err
+		}
+	}
+	for i, arg := range obj.Args {
+		if len(arg) == 0 {
+			return fmt.Errorf("invalid argument %d: empty", i)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the script has bytecode, and checks its type arguments and that its
+// arguments are set.
+func (obj *Script) Validate() error {
+	if len(obj.Code) == 0 {
+		return errors.New("invalid script: empty bytecode")
+	}
+	for _, arg := range obj.TyArgs {
+		if err := ValidateTypeTag(arg); err != nil {
+			return err
+		}
+	}
+	for i, arg := range obj.Args {
+		if arg == nil {
+			return fmt.Errorf("invalid argument %d: missing", i)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the bundle holds modules and that none of them is empty.
+func (obj *ModuleBundle) Validate() error {
+	if len(obj.Codes) == 0 {
+		return errors.New("invalid module bundle: no modules")
+	}
+	for i, module := range obj.Codes {
+		if len(module.Code) == 0 {
+			return fmt.Errorf("invalid module %d: empty bytecode", i)
+		}
+	}
+	return nil
+}
+
+// ValidateTransactionPayload checks the fields of a decoded payload, e.g. from untrusted
+// input, beyond what the BCS decoder checks. Write-set payloads, which only the core
+// resources account can send, are not checked.
+func ValidateTransactionPayload(payload TransactionPayload) error {
+	switch payload := payload.(type) {
+	case nil:
+		return errors.New("missing transaction payload")
+	case *TransactionPayload__Script:
+		if payload == nil {
+			return errors.New("missing transaction payload")
+		}
+		return payload.Value.Validate()
+	case *TransactionPayload__ModuleBundle:
+		if payload == nil {
+			return errors.New("missing transaction payload")
+		}
+		return payload.Value.Validate()
+	case *TransactionPayload__ScriptFunction:
+		if payload == nil {
+			return errors.New("missing transaction payload")
+		}
+		return payload.Value.Validate()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewIdentifier(t *testing.T) {
+	for _, s := range []string{"coin", "coin_2", "_coin", "AptosCoin"} {
+		if _, err := NewIdentifier(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "_", "2a", "a-b", "0x::", "é", strings.Repeat("a", MaxIdentifierLength+1)} {
+		if _, err := NewIdentifier(s); err == nil {
+			t.Errorf("accepted the identifier %.20q", s)
+		}
+	}
+}
+
+func TestValidateTypeTag(t *testing.T) {
+	tag, err := ParseTypeTag("0x1::m::T<vector<u8>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateTypeTag(tag); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []TypeTag{
+		nil,
+		(*TypeTag__Vector)(nil),
+		(*TypeTag__Struct)(nil),
+		VectorTypeTag(nil),
+		VectorTypeTag(StructTypeTag(CoreCodeAddress, "m", "T", nil)),
+		StructTypeTag(CoreCodeAddress, "m", "T-1"),
+	} {
+		if err := ValidateTypeTag(tag); err == nil {
+			t.Errorf("accepted the type tag %#v", tag)
+		}
+	}
+}
+
+func TestBcsSerializeTypeTags(t *testing.T) {
+	coin := StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")
+	encoded, err := BcsSerializeTypeTags([]TypeTag{coin, U64TypeTag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinBytes, err := coin.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := append(append([]byte{2}, coinBytes...), 2); !bytes.Equal(encoded, expected) {
+		t.Fatalf("unexpected encoding %x, expected %x", encoded, expected)
+	}
+
+	invalid := StructTypeTag(CoreCodeAddress, "aptos_coin", "Aptos-Coin")
+	if _, err := BcsSerializeTypeTags([]TypeTag{coin, invalid}); err == nil || !strings.HasPrefix(err.Error(), "type argument 1: ") {
+		t.Fatalf("expected an error for type argument 1, got %v", err)
+	}
+}
+
+func TestValidateTransactionPayload(t *testing.T) {
+	coin := StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")
+	valid := ScriptFunction{
+		Module:   ModuleId{Address: CoreCodeAddress, Name: "coin"},
+		Function: "transfer",
+		TyArgs:   []TypeTag{coin},
+		Args:     [][]byte{{1}},
+	}
+	if err := ValidateTransactionPayload(&TransactionPayload__ScriptFunction{Value: valid}); err != nil {
+		t.Fatal(err)
+	}
+
+	invalidTag := StructTypeTag(CoreCodeAddress, "m", "b-d")
+	for name, change := range map[string]func(*ScriptFunction){
+		"module":        func(f *ScriptFunction) { f.Module.Name = "b-d" },
+		"function":      func(f *ScriptFunction) { f.Function = "2transfer" },
+		"type argument": func(f *ScriptFunction) { f.TyArgs = []TypeTag{VectorTypeTag(invalidTag)} },
+		"missing type":  func(f *ScriptFunction) { f.TyArgs = []TypeTag{VectorTypeTag(nil)} },
+		"argument":      func(f *ScriptFunction) { f.Args = [][]byte{{1}, {}} },
+	} {
+		invalid := valid
+		change(&invalid)
+		if err := ValidateTransactionPayload(&TransactionPayload__ScriptFunction{Value: invalid}); err == nil {
+			t.Errorf("accepted an invalid %s", name)
+		}
+	}
+
+	// A payload decoded from BCS, which accepts any UTF-8 identifier.
+	payload := &TransactionPayload__ScriptFunction{Value: valid}
+	payload.Value.Function = "b-d"
+	encoded, err := payload.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := BcsDeserializeTransactionPayload(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateTransactionPayload(decoded); err == nil || !strings.Contains(err.Error(), `"b-d"`) {
+		t.Fatalf("expected the identifier \"b-d\" to be rejected, got %v", err)
+	}
+
+	if err := ValidateTransactionPayload(&TransactionPayload__Script{}); err == nil {
+		t.Fatal("accepted a script without bytecode")
+	}
+	if err := ValidateTransactionPayload(&TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{}}}}); err == nil {
+		t.Fatal("accepted an empty module")
+	}
+	if err := ValidateTransactionPayload(nil); err == nil {
+		t.Fatal("accepted a missing payload")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// U128 is a Move `u128`. It has the same layout as `serde.Uint128`, which is what the
+// generated encoders take, so `serde.Uint128(value)` converts between the two.
+type U128 serde.Uint128
+
+// U256 is a Move `u256`, stored as four 64-bit limbs, least significant first.
+type U256 [4]uint64
+
+var (
+	maxU128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	maxU256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+)
+
+// Split a non-negative integer into 64-bit limbs, least significant first.
+func bigToLimbs(value *big.Int, limbs []uint64, max *big.Int, name string) error {
+	if value.Sign() < 0 {
+		return fmt.Errorf("cannot convert negative value %v to %s", value, name)
+	}
+	if value.Cmp(max) > 0 {
+		return fmt.Errorf("value %v is too large for %s", value, name)
+	}
+	mask := new(big.Int).SetUint64(^uint64(0))
+	rest := new(big.Int).Set(value)
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(rest, mask).Uint64()
+		rest.Rsh(rest, 64)
+	}
+	return nil
+}
+
+func limbsToBig(limbs []uint64) *big.Int {
+	value := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		value.Lsh(value, 64)
+		value.Or(value, new(big.Int).SetUint64(limbs[i]))
+	}
+	return value
+}
+
+// NewU128 converts a `big.Int` into a `U128`, failing if it is negative or too large.
+func NewU128(value *big.Int) (U128, error) {
+	var limbs [2]uint64
+	if err := bigToLimbs(value, limbs[:], maxU128, "u128"); err != nil {
+		return U128{}, err
+	}
+	return U128{Low: limbs[0], High: limbs[1]}, nil
+}
+
+// BigInt returns the value as a `big.Int`.
+func (obj U128) BigInt() *big.Int {
+	return limbsToBig([]uint64{obj.Low, obj.High})
+}
+
+func (obj U128) String() string {
+	return obj.BigInt().String()
+}
+
+func (obj *U128) Serialize(serializer serde.Serializer) error {
+	return serializer.SerializeU128(serde.Uint128(*obj))
+}
+
+func (obj *U128) BcsSerialize() ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("Cannot serialize null object")
+	}
+	serializer := bcs.NewSerializer()
+	if err := obj.Serialize(serializer); err != nil {
+		return nil, err
+	}
+	return serializer.GetBytes(), nil
+}
+
+func DeserializeU128(deserializer serde.Deserializer) (U128, error) {
+	value, err := deserializer.DeserializeU128()
+	return U128(value), err
+}
+
+func BcsDeserializeU128(input []byte) (U128, error) {
+	deserializer := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeU128(deserializer)
+	if err == nil && deserializer.GetBufferOffset() < uint64(len(input)) {
+		return obj, fmt.Errorf("Some input bytes were not read")
+	}
+	return obj, err
+}
+
+// NewU256 converts a `big.Int` into a `U256`, failing if it is negative or too large.
+func NewU256(value *big.Int) (U256, error) {
+	var obj U256
+	err := bigToLimbs(value, obj[:], maxU256, "u256")
+	return obj, err
+}
+
+// BigInt returns the value as a `big.Int`.
+func (obj U256) BigInt() *big.Int {
+	return limbsToBig(obj[:])
+}
+
+func (obj U256) String() string {
+	return obj.BigInt().String()
+}
+
+func (obj *U256) Serialize(serializer serde.Serializer) error {
+	for _, limb := range obj {
+		if err := serializer.SerializeU64(limb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (obj *U256) BcsSerialize() ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("Cannot serialize null object")
+	}
+	serializer := bcs.NewSerializer()
+	if err := obj.Serialize(serializer); err != nil {
+		return nil, err
+	}
+	return serializer.GetBytes(), nil
+}
+
+func DeserializeU256(deserializer serde.Deserializer) (U256, error) {
+	var obj U256
+	for i := range obj {
+		limb, err := deserializer.DeserializeU64()
+		if err != nil {
+			return obj, err
+		}
+		obj[i] = limb
+	}
+	return obj, nil
+}
+
+func BcsDeserializeU256(input []byte) (U256, error) {
+	deserializer := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeU256(deserializer)
+	if err == nil && deserializer.GetBufferOffset() < uint64(len(input)) {
+		return obj, fmt.Errorf("Some input bytes were not read")
+	}
+	return obj, err
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestNewU128(t *testing.T) {
+	max, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	value, err := NewU128(max)
+	if err != nil || value.Low != ^uint64(0) || value.High != ^uint64(0) || value.String() != max.String() {
+		t.Fatalf("unexpected u128 %v: %v", value, err)
+	}
+	if _, err := NewU128(new(big.Int).Add(max, big.NewInt(1))); err == nil {
+		t.Fatal("accepted 2^128")
+	}
+	if _, err := NewU128(big.NewInt(-1)); err == nil {
+		t.Fatal("accepted -1")
+	}
+
+	encoded, err := value.BcsSerialize()
+	if err != nil || !bytes.Equal(encoded, bytes.Repeat([]byte{0xff}, 16)) {
+		t.Fatalf("unexpected encoding %x: %v", encoded, err)
+	}
+	if decoded, err := BcsDeserializeU128(encoded); err != nil || decoded != value {
+		t.Fatalf("failed to decode %x: %v", encoded, err)
+	}
+}
+
+func TestNewU256(t *testing.T) {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	if value, err := NewU256(max); err != nil || value.BigInt().Cmp(max) != 0 {
+		t.Fatalf("unexpected u256 %v: %v", value, err)
+	}
+	if _, err := NewU256(new(big.Int).Add(max, big.NewInt(1))); err == nil {
+		t.Fatal("accepted 2^256")
+	}
+	if _, err := NewU256(big.NewInt(-1)); err == nil {
+		t.Fatal("accepted -1")
+	}
+
+	value, err := NewU256(big.NewInt(0x0102))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := value.BcsSerialize()
+	if err != nil || !bytes.Equal(encoded, append([]byte{2, 1}, make([]byte, 30)...)) {
+		t.Fatalf("unexpected encoding %x: %v", encoded, err)
+	}
+	if decoded, err := BcsDeserializeU256(encoded); err != nil || decoded != value || decoded.String() != "258" {
+		t.Fatalf("failed to decode %x: %v", encoded, err)
+	}
+	if _, err := BcsDeserializeU256(encoded[:31]); err == nil {
+		t.Fatal("decoded a truncated u256")
+	}
+}
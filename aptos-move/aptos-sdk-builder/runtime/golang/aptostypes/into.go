@@ -0,0 +1,138 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "errors"
+
+// BcsDeserializeTransactionPayloadInto decodes a transaction payload like
+// `BcsDeserializeTransactionPayload` and stores it in `*dst`. If `*dst` already holds a
+// script function payload and the input is another one, its memory is reused: the
+// argument slices are overwritten when their capacity allows and identifiers equal to the
+// previous ones are kept, so that a steady-state decoding loop barely allocates.
+//
+// The previous value of `*dst` must therefore not be retained by the caller. If an error is
+// returned, `*dst` is left in an unspecified state.
+func BcsDeserializeTransactionPayloadInto(dst *TransactionPayload, input []byte) error {
+	if input == nil {
+		return errors.New("cannot deserialize null array")
+	}
+	d := NewDeserializer(input, DefaultDeserializerOptions)
+	if err := d.deserializeTransactionPayloadInto(dst); err != nil {
+		return err
+	}
+	if d.Remaining() > 0 {
+		return errors.New("some input bytes were not read")
+	}
+	return nil
+}
+
+// Index of the `ScriptFunction` variant of `TransactionPayload`.
+const scriptFunctionPayloadIndex = 3
+
+func (d *Deserializer) deserializeTransactionPayloadInto(dst *TransactionPayload) error {
+	start := d.offset
+	index, err := d.DeserializeVariantIndex()
+	if err != nil {
+		return err
+	}
+	payload, ok := (*dst).(*TransactionPayload__ScriptFunction)
+	if index != scriptFunctionPayloadIndex || !ok || payload == nil {
+		// Nothing to reuse: decode the payload again from its variant index.
+		d.offset = start
+		*dst, err = DeserializeTransactionPayload(d)
+		return err
+	}
+	if err := d.IncreaseContainerDepth(); err != nil {
+		return err
+	}
+	if err := d.deserializeScriptFunctionInto(&payload.Value); err != nil {
+		return err
+	}
+	d.DecreaseContainerDepth()
+	return nil
+}
+
+// Same as the generated `DeserializeScriptFunction`, reusing the memory of `obj`.
+func (d *Deserializer) deserializeScriptFunctionInto(obj *ScriptFunction) error {
+	if err := d.IncreaseContainerDepth(); err != nil {
+		return err
+	}
+	if err := d.IncreaseContainerDepth(); err != nil {
+		return err
+	}
+	address, err := DeserializeAccountAddress(d)
+	if err != nil {
+		return err
+	}
+	obj.Module.Address = address
+	if obj.Module.Name, err = d.deserializeIdentifierInto(obj.Module.Name); err != nil {
+		return err
+	}
+	d.DecreaseContainerDepth()
+	if obj.Function, err = d.deserializeIdentifierInto(obj.Function); err != nil {
+		return err
+	}
+
+	length, err := d.DeserializeLen()
+	if err != nil {
+		return err
+	}
+	tyArgs := obj.TyArgs[:0]
+	for i := uint64(0); i < length; i++ {
+		tag, err := DeserializeTypeTag(d)
+		if err != nil {
+			return err
+		}
+		tyArgs = append(tyArgs, tag)
+	}
+	obj.TyArgs = tyArgs
+
+	if length, err = d.DeserializeLen(); err != nil {
+		return err
+	}
+	args := obj.Args
+	if uint64(cap(args)) >= length {
+		// Elements past the length are previous arguments too, whose buffers can be reused.
+		args = args[:length]
+	} else {
+		args = make([][]byte, length)
+		copy(args, obj.Args[:cap(obj.Args)])
+	}
+	for i := range args {
+		if args[i], err = d.deserializeBytesInto(args[i], "bytes"); err != nil {
+			return err
+		}
+	}
+	obj.Args = args
+	d.DecreaseContainerDepth()
+	return nil
+}
+
+// Decode an identifier, returning `previous` instead of a new string if they are equal.
+func (d *Deserializer) deserializeIdentifierInto(previous Identifier) (Identifier, error) {
+	if err := d.IncreaseContainerDepth(); err != nil {
+		return "", err
+	}
+	buf, err := d.readBytes("string")
+	if err != nil {
+		return "", err
+	}
+	d.DecreaseContainerDepth()
+	if string(buf) == string(previous) {
+		return previous, nil
+	}
+	return Identifier(buf), nil
+}
+
+// Decode a byte vector into the memory of `dst` if it is large enough.
+func (d *Deserializer) deserializeBytesInto(dst []byte, what string) ([]byte, error) {
+	if d.zeroCopy {
+		return d.deserializeBytes(what)
+	}
+	buf, err := d.readBytes(what)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst[:0], buf...), nil
+}
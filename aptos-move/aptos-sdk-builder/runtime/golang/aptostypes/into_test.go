@@ -0,0 +1,83 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Payloads of varying shapes, so that decoding them in a loop shrinks and grows the
+// reused slices.
+func intoTestInputs(t testing.TB) [][]byte {
+	coin := StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")
+	var inputs [][]byte
+	for i := 0; i < 4; i++ {
+		payload := &TransactionPayload__ScriptFunction{Value: ScriptFunction{
+			Module:   ModuleId{Address: CoreCodeAddress, Name: "coin"},
+			Function: "transfer",
+			TyArgs:   []TypeTag{coin, U8TypeTag}[:i%3],
+			Args:     [][]byte{bytes.Repeat([]byte{byte(i)}, 32), make([]byte, 8*i), {}}[:i%4],
+		}}
+		input, err := payload.BcsSerialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, input)
+	}
+	script, err := (&TransactionPayload__Script{Value: Script{Code: []byte{1, 2}}}).BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(inputs, script)
+}
+
+func TestBcsDeserializeTransactionPayloadInto(t *testing.T) {
+	var dst TransactionPayload
+	for round := 0; round < 3; round++ {
+		for _, input := range intoTestInputs(t) {
+			if err := BcsDeserializeTransactionPayloadInto(&dst, input); err != nil {
+				t.Fatal(err)
+			}
+			if output, err := dst.BcsSerialize(); err != nil || !bytes.Equal(output, input) {
+				t.Fatalf("decoding %x gave %x", input, output)
+			}
+		}
+	}
+
+	input := intoTestInputs(t)[1]
+	if err := BcsDeserializeTransactionPayloadInto(&dst, append(input, 0)); err == nil {
+		t.Fatal("accepted a trailing byte")
+	}
+	if err := BcsDeserializeTransactionPayloadInto(&dst, input[:len(input)-1]); err == nil {
+		t.Fatal("accepted a truncated payload")
+	}
+}
+
+// Compare decoding a coin transfer into the same destination with decoding it afresh:
+//
+//	go test -run NONE -bench BcsDeserializeTransactionPayloadInto
+func BenchmarkBcsDeserializeTransactionPayloadInto(b *testing.B) {
+	input, err := coinTransferPayload().BcsSerialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("into", func(b *testing.B) {
+		var dst TransactionPayload
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := BcsDeserializeTransactionPayloadInto(&dst, input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := BcsDeserializeTransactionPayload(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
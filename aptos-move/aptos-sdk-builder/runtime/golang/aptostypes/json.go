@@ -0,0 +1,165 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// The JSON encoding of type tags is their canonical string form, as emitted by the REST
+// API, e.g. "u64", "vector<u8>" or "0x1::coin::Coin<0x1::aptos_coin::AptosCoin>". It is
+// unrelated to the BCS encoding. Addresses are written in their `ToHexLiteral` form, like
+// the node does, and read in any form accepted by `ParseAccountAddress`.
+
+// UnmarshalTypeTagJSON decodes a JSON string holding a type tag of any kind. It is meant
+// for fields of the `TypeTag` interface type, which `encoding/json` cannot decode into.
+func UnmarshalTypeTagJSON(data []byte) (TypeTag, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return ParseTypeTag(s)
+}
+
+func (obj StructTag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.String())
+}
+
+func (obj *StructTag) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	tag, err := ParseStructTag(s)
+	if err != nil {
+		return err
+	}
+	*obj = tag
+	return nil
+}
+
+func (obj *TypeTag__Bool) MarshalJSON() ([]byte, error)    { return json.Marshal(obj.String()) }
+func (obj *TypeTag__U8) MarshalJSON() ([]byte, error)      { return json.Marshal(obj.String()) }
+func (obj *TypeTag__U64) MarshalJSON() ([]byte, error)     { return json.Marshal(obj.String()) }
+func (obj *TypeTag__U128) MarshalJSON() ([]byte, error)    { return json.Marshal(obj.String()) }
+func (obj *TypeTag__Address) MarshalJSON() ([]byte, error) { return json.Marshal(obj.String()) }
+func (obj *TypeTag__Signer) MarshalJSON() ([]byte, error)  { return json.Marshal(obj.String()) }
+func (obj *TypeTag__Vector) MarshalJSON() ([]byte, error)  { return json.Marshal(obj.String()) }
+func (obj *TypeTag__Struct) MarshalJSON() ([]byte, error)  { return json.Marshal(obj.String()) }
+
+func (obj *TypeTag__Bool) UnmarshalJSON(data []byte) error    { return expectTypeTagJSON(data, obj) }
+func (obj *TypeTag__U8) UnmarshalJSON(data []byte) error      { return expectTypeTagJSON(data, obj) }
+func (obj *TypeTag__U64) UnmarshalJSON(data []byte) error     { return expectTypeTagJSON(data, obj) }
+func (obj *TypeTag__U128) UnmarshalJSON(data []byte) error    { return expectTypeTagJSON(data, obj) }
+func (obj *TypeTag__Address) UnmarshalJSON(data []byte) error { return expectTypeTagJSON(data, obj) }
+func (obj *TypeTag__Signer) UnmarshalJSON(data []byte) error  { return expectTypeTagJSON(data, obj) }
+
+func (obj *TypeTag__Vector) UnmarshalJSON(data []byte) error {
+	tag, err := UnmarshalTypeTagJSON(data)
+	if err != nil {
+		return err
+	}
+	vector, ok := tag.(*TypeTag__Vector)
+	if !ok {
+		return fmt.Errorf("type tag `%s` is not a vector", typeTagString(tag))
+	}
+	*obj = *vector
+	return nil
+}
+
+func (obj *TypeTag__Struct) UnmarshalJSON(data []byte) error {
+	return obj.Value.UnmarshalJSON(data)
+}
+
+// Decode a type tag without payload and check that it is the same kind as `want`.
+func expectTypeTagJSON(data []byte, want fmt.Stringer) error {
+	tag, err := UnmarshalTypeTagJSON(data)
+	if err != nil {
+		return err
+	}
+	if s := typeTagString(tag); s != want.String() {
+		return fmt.Errorf("type tag `%s` is not a `%s`", s, want)
+	}
+	return nil
+}
+
+// ScriptFunctionPayloadJSON is the JSON form of a script function payload in the REST API,
+// e.g. `{"type": "script_function_payload", "function": "0x1::coin::transfer", ...}`.
+type ScriptFunctionPayloadJSON struct {
+	Type          string        `json:"type"`
+	Function      string        `json:"function"`
+	TypeArguments []TypeTag     `json:"type_arguments"`
+	Arguments     []interface{} `json:"arguments"`
+}
+
+// NewScriptFunctionPayloadJSON returns the JSON form of `obj`, given the JSON values of its
+// arguments, e.g. built with `ArgumentJSON`. If `arguments` is nil, the BCS encoding of
+// each argument is hex-encoded instead, since the argument types are unknown here.
+func NewScriptFunctionPayloadJSON(obj *ScriptFunction, arguments []interface{}) ScriptFunctionPayloadJSON {
+	if arguments == nil {
+		arguments = make([]interface{}, len(obj.Args))
+		for i, arg := range obj.Args {
+			arguments[i] = ArgumentJSON(arg)
+		}
+	}
+	tyArgs := obj.TyArgs
+	if tyArgs == nil {
+		tyArgs = []TypeTag{}
+	}
+	return ScriptFunctionPayloadJSON{
+		Type:          "script_function_payload",
+		Function:      obj.Module.String() + "::" + string(obj.Function),
+		TypeArguments: tyArgs,
+		Arguments:     arguments,
+	}
+}
+
+// MarshalJSON returns the REST API form of the payload, with the BCS bytes of the arguments
+// hex-encoded since their types are unknown here. To get the arguments of the script
+// functions of the framework as their natural JSON values, use `MarshalPayloadJSON` of the
+// generated stdlib package instead.
+func (obj *TransactionPayload__ScriptFunction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewScriptFunctionPayloadJSON(&obj.Value, nil))
+}
+
+// ArgumentJSON converts a script function argument to the JSON value that the node uses
+// for it: numbers for `u8`, decimal strings for `u64` and `u128`, hex strings for
+// addresses and `vector<u8>`, and arrays for other vectors. Other values are returned as
+// is.
+func ArgumentJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case uint64:
+		return fmt.Sprint(value)
+	case serde.Uint128:
+		return U128(value).String()
+	case AccountAddress:
+		return value.ToHexLiteral()
+	case []byte:
+		return "0x" + hex.EncodeToString(value)
+	case []bool:
+		return vectorJSON(len(value), func(i int) interface{} { return value[i] })
+	case []uint64:
+		return vectorJSON(len(value), func(i int) interface{} { return ArgumentJSON(value[i]) })
+	case []serde.Uint128:
+		return vectorJSON(len(value), func(i int) interface{} { return ArgumentJSON(value[i]) })
+	case []AccountAddress:
+		return vectorJSON(len(value), func(i int) interface{} { return ArgumentJSON(value[i]) })
+	case [][]byte:
+		return vectorJSON(len(value), func(i int) interface{} { return ArgumentJSON(value[i]) })
+	}
+	return value
+}
+
+// Build a JSON array, which is never null even if the vector is nil.
+func vectorJSON(length int, item func(i int) interface{}) []interface{} {
+	values := make([]interface{}, length)
+	for i := range values {
+		values[i] = item(i)
+	}
+	return values
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTypeTagJSON(t *testing.T) {
+	coin := StructTypeTag(CoreCodeAddress, "coin", "Coin", VectorTypeTag(StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")))
+	for _, test := range []struct {
+		tag TypeTag
+		// The JSON string, unquoted: `encoding/json` escapes angle brackets.
+		want string
+		// A pointer to a zero value of the type of `tag`.
+		decoded interface{}
+	}{
+		{&TypeTag__Bool{}, "bool", new(TypeTag__Bool)},
+		{&TypeTag__U8{}, "u8", new(TypeTag__U8)},
+		{&TypeTag__U64{}, "u64", new(TypeTag__U64)},
+		{&TypeTag__U128{}, "u128", new(TypeTag__U128)},
+		{&TypeTag__Address{}, "address", new(TypeTag__Address)},
+		{&TypeTag__Signer{}, "signer", new(TypeTag__Signer)},
+		{VectorTypeTag(&TypeTag__U8{}), "vector<u8>", new(TypeTag__Vector)},
+		{coin, "0x1::coin::Coin<vector<0x1::aptos_coin::AptosCoin>>", new(TypeTag__Struct)},
+	} {
+		output, err := json.Marshal(test.tag)
+		var s string
+		if err == nil {
+			err = json.Unmarshal(output, &s)
+		}
+		if err != nil || s != test.want {
+			t.Errorf("expected %q, got %s, %v", test.want, output, err)
+			continue
+		}
+		if err := json.Unmarshal(output, test.decoded); err != nil || !reflect.DeepEqual(test.decoded, test.tag) {
+			t.Errorf("%s: failed to decode into %T: %v", output, test.decoded, err)
+		}
+		if tag, err := UnmarshalTypeTagJSON(output); err != nil || !reflect.DeepEqual(tag, test.tag) {
+			t.Errorf("%s: failed to decode a type tag: %v", output, err)
+		}
+	}
+
+	var u64 TypeTag__U64
+	if err := json.Unmarshal([]byte(`"u8"`), &u64); err == nil {
+		t.Fatal("decoded a u8 into a u64")
+	}
+	var vector TypeTag__Vector
+	if err := json.Unmarshal([]byte(`"u8"`), &vector); err == nil {
+		t.Fatal("decoded a u8 into a vector")
+	}
+	if _, err := UnmarshalTypeTagJSON([]byte(`"u7"`)); err == nil {
+		t.Fatal("decoded an unknown keyword")
+	}
+
+	// Addresses are accepted in their full form too.
+	var tag StructTag
+	full := `"0x0000000000000000000000000000000000000000000000000000000000000001::aptos_coin::AptosCoin"`
+	if err := json.Unmarshal([]byte(full), &tag); err != nil || tag.String() != "0x1::aptos_coin::AptosCoin" {
+		t.Fatalf("failed to decode %s: %v", full, err)
+	}
+}
+
+func TestScriptFunctionPayloadJSON(t *testing.T) {
+	recipient := AccountAddress{30: 0xca, 31: 0xfe}
+	amount := []byte{5, 0, 0, 0, 0, 0, 0, 0}
+	payload := &TransactionPayload__ScriptFunction{Value: ScriptFunction{
+		Module:   ModuleId{Address: CoreCodeAddress, Name: "coin"},
+		Function: "transfer",
+		TyArgs:   []TypeTag{StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")},
+		Args:     [][]byte{recipient[:], amount},
+	}}
+
+	// The form of a coin transfer returned by the REST API, with the arguments hex-encoded.
+	output, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"script_function_payload","function":"0x1::coin::transfer",` +
+		`"type_arguments":["0x1::aptos_coin::AptosCoin"],` +
+		`"arguments":["0x000000000000000000000000000000000000000000000000000000000000cafe","0x0500000000000000"]}`
+	if string(output) != want {
+		t.Fatalf("unexpected JSON\n%s\nexpected\n%s", output, want)
+	}
+
+	// The form with the arguments as their natural JSON values.
+	view := NewScriptFunctionPayloadJSON(&payload.Value, []interface{}{ArgumentJSON(recipient), ArgumentJSON(uint64(5))})
+	if output, err = json.Marshal(view); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"type":"script_function_payload","function":"0x1::coin::transfer",` +
+		`"type_arguments":["0x1::aptos_coin::AptosCoin"],"arguments":["0xcafe","5"]}`
+	if string(output) != want {
+		t.Fatalf("unexpected JSON\n%s\nexpected\n%s", output, want)
+	}
+}
+
+func TestArgumentJSON(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		want  string
+	}{
+		{uint8(7), `7`},
+		{uint64(1) << 63, `"9223372036854775808"`},
+		{ReservedVMAddress, `"0x0"`},
+		{AptosRootAddress, `"0xa550c18"`},
+		{[]byte{0xca, 0xfe}, `"0xcafe"`},
+		{[]uint64{1, 2}, `["1","2"]`},
+		{[]AccountAddress(nil), `[]`},
+	} {
+		output, err := json.Marshal(ArgumentJSON(test.value))
+		if err != nil || string(output) != test.want {
+			t.Errorf("%v: expected %s, got %s, %v", test.value, test.want, output, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+// testKeyPair returns the Ed25519 key pair derived from `seed`, so that signing tests give the
+// same signatures on every run and can be compared with golden values. Unlike
+// `ed25519.GenerateKey`, it reads no randomness.
+func testKeyPair(seed [32]byte) (ed25519.PrivateKey, ed25519.PublicKey) {
+	privKey := ed25519.NewKeyFromSeed(seed[:])
+	return privKey, privKey.Public().(ed25519.PublicKey)
+}
+
+func TestTestKeyPairIsDeterministic(t *testing.T) {
+	// Test 1 of RFC 8032, section 7.1.
+	var seed [32]byte
+	if _, err := hex.Decode(seed[:], []byte("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")); err != nil {
+		t.Fatal(err)
+	}
+	privKey, pubKey := testKeyPair(seed)
+	if got := hex.EncodeToString(pubKey); got != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" {
+		t.Fatalf("unexpected public key %s", got)
+	}
+	otherPrivKey, otherPubKey := testKeyPair(seed)
+	if !bytes.Equal(privKey, otherPrivKey) || !bytes.Equal(pubKey, otherPubKey) {
+		t.Fatal("the same seed gave different key pairs")
+	}
+
+	txn := RawTransaction{SequenceNumber: 1, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	first, err := txn.SignEd25519(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := txn.SignEd25519(otherPrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstBytes, err := first.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondBytes, err := second.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(firstBytes, secondBytes) {
+		t.Fatal("the same key pair gave different signatures")
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// BcsSerializedLength returns `len(value.BcsSerialize())` without allocating the output.
+// This is useful to size a buffer before framing several encoded values together.
+func BcsSerializedLength(value Serializable) (int, error) {
+	counter := new(lengthCounter)
+	if err := value.Serialize(counter); err != nil {
+		return 0, err
+	}
+	return int(counter.length), nil
+}
+
+// lengthCounter implements `serde.Serializer` by counting the bytes that the BCS
+// serializer would write.
+type lengthCounter struct {
+	length uint64
+	depth  uint64
+}
+
+var _ serde.Serializer = (*lengthCounter)(nil)
+
+func (c *lengthCounter) IncreaseContainerDepth() error {
+	if c.depth >= bcs.MaxContainerDepth {
+		return errors.New("max container depth exceeded")
+	}
+	c.depth++
+	return nil
+}
+
+func (c *lengthCounter) DecreaseContainerDepth() {
+	c.depth--
+}
+
+func (c *lengthCounter) GetBufferOffset() uint64 {
+	return c.length
+}
+
+// GetBytes always returns nil since nothing is written.
+func (c *lengthCounter) GetBytes() []byte {
+	return nil
+}
+
+func (c *lengthCounter) SerializeStr(value string) error {
+	if err := c.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	c.length += uint64(len(value))
+	return nil
+}
+
+func (c *lengthCounter) SerializeBytes(value []byte) error {
+	if err := c.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	c.length += uint64(len(value))
+	return nil
+}
+
+func (c *lengthCounter) SerializeBool(value bool) error {
+	c.length++
+	return nil
+}
+
+func (c *lengthCounter) SerializeUnit(value struct{}) error {
+	return nil
+}
+
+func (c *lengthCounter) SerializeChar(value rune) error {
+	return errors.New("BCS does not support char")
+}
+
+func (c *lengthCounter) SerializeF32(value float32) error {
+	return errors.New("BCS does not support f32")
+}
+
+func (c *lengthCounter) SerializeF64(value float64) error {
+	return errors.New("BCS does not support f64")
+}
+
+func (c *lengthCounter) SerializeU8(value uint8) error {
+	c.length++
+	return nil
+}
+
+func (c *lengthCounter) SerializeU16(value uint16) error {
+	c.length += 2
+	return nil
+}
+
+func (c *lengthCounter) SerializeU32(value uint32) error {
+	c.length += 4
+	return nil
+}
+
+func (c *lengthCounter) SerializeU64(value uint64) error {
+	c.length += 8
+	return nil
+}
+
+func (c *lengthCounter) SerializeU128(value serde.Uint128) error {
+	c.length += 16
+	return nil
+}
+
+func (c *lengthCounter) SerializeI8(value int8) error {
+	c.length++
+	return nil
+}
+
+func (c *lengthCounter) SerializeI16(value int16) error {
+	c.length += 2
+	return nil
+}
+
+func (c *lengthCounter) SerializeI32(value int32) error {
+	c.length += 4
+	return nil
+}
+
+func (c *lengthCounter) SerializeI64(value int64) error {
+	c.length += 8
+	return nil
+}
+
+func (c *lengthCounter) SerializeI128(value serde.Int128) error {
+	c.length += 16
+	return nil
+}
+
+func (c *lengthCounter) countUleb128(value uint32) {
+	c.length++
+	for value >= 0x80 {
+		c.length++
+		value >>= 7
+	}
+}
+
+func (c *lengthCounter) SerializeLen(value uint64) error {
+	if value > bcs.MaxSequenceLength {
+		return errors.New("length is too large")
+	}
+	c.countUleb128(uint32(value))
+	return nil
+}
+
+func (c *lengthCounter) SerializeVariantIndex(value uint32) error {
+	c.countUleb128(value)
+	return nil
+}
+
+func (c *lengthCounter) SerializeOptionTag(value bool) error {
+	c.length++
+	return nil
+}
+
+// SortMapEntries is a no-op: reordering entries does not change the total length.
+func (c *lengthCounter) SortMapEntries(offsets []uint64) {}
@@ -0,0 +1,33 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "testing"
+
+func TestBcsSerializedLength(t *testing.T) {
+	coin := StructTypeTag(CoreCodeAddress, "coin", "Coin", VectorTypeTag(StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")))
+	for _, value := range []interface {
+		Serializable
+		BcsSerialize() ([]byte, error)
+	}{
+		coinTransferPayload(),
+		// A length of 200 takes two uleb128 bytes.
+		&TransactionPayload__ScriptFunction{Value: ScriptFunction{
+			Module:   ModuleId{Name: "coin"},
+			Function: "transfer",
+			TyArgs:   []TypeTag{coin, coin},
+			Args:     [][]byte{make([]byte, 200), {3}},
+		}},
+		&TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{{Code: make([]byte, 1<<20)}}}},
+		&SignedTransaction{RawTxn: RawTransaction{Payload: coinTransferPayload()}, Authenticator: &TransactionAuthenticator__Ed25519{}},
+	} {
+		want, err := value.BcsSerialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := BcsSerializedLength(value); err != nil || n != len(want) {
+			t.Errorf("%T: expected %d bytes, got %d, %v", value, len(want), n, err)
+		}
+	}
+}
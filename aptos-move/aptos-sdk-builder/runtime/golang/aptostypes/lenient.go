@@ -0,0 +1,71 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"fmt"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// LenientValue is a value decoded by `BcsDeserializeLenient` together with the bytes which
+// followed it in the input, e.g. the fields appended to a struct by a newer version of the
+// node. Serializing it writes the value then the extra bytes, so that the original input is
+// reproduced.
+//
+// BCS does not delimit fields: only fields added at the very end of the decoded value can be
+// preserved this way, not those added to a nested struct followed by other fields.
+type LenientValue struct {
+	Value Serializable
+	Extra []byte
+}
+
+func (obj *LenientValue) Serialize(serializer serde.Serializer) error {
+	if err := obj.Value.Serialize(serializer); err != nil {
+		return err
+	}
+	if s, ok := serializer.(*Serializer); ok {
+		s.buf = append(s.buf, obj.Extra...)
+		return nil
+	}
+	for _, b := range obj.Extra {
+		if err := serializer.SerializeU8(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (obj *LenientValue) BcsSerialize() ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot serialize null object")
+	}
+	return AppendBcs(nil, obj)
+}
+
+// BcsDeserializeLenient decodes a value from the start of `input` with `deserialize`, and
+// keeps the bytes which follow it instead of rejecting them like the `BcsDeserialize*`
+// functions do:
+//
+//	value, err := aptostypes.BcsDeserializeLenient(input, func(d *aptostypes.Deserializer) (aptostypes.Serializable, error) {
+//		txn, err := aptostypes.DeserializeRawTransaction(d)
+//		return &txn, err
+//	})
+//
+// `Extra` is nil if the input holds no trailing bytes. Otherwise it aliases `input`.
+func BcsDeserializeLenient(
+	input []byte,
+	deserialize func(*Deserializer) (Serializable, error),
+) (*LenientValue, error) {
+	d := NewDeserializer(input, DefaultDeserializerOptions)
+	value, err := deserialize(d)
+	if err != nil {
+		return nil, err
+	}
+	obj := &LenientValue{Value: value}
+	if d.Remaining() > 0 {
+		obj.Extra = d.RemainingBytes()
+	}
+	return obj, nil
+}
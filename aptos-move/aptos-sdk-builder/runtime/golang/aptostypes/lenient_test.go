@@ -0,0 +1,53 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+func deserializeLenientRawTransaction(d *Deserializer) (Serializable, error) {
+	txn, err := DeserializeRawTransaction(d)
+	return &txn, err
+}
+
+func TestBcsDeserializeLenient(t *testing.T) {
+	raw := RawTransaction{SequenceNumber: 3, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	rawBytes, err := raw.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := BcsDeserializeLenient(rawBytes, deserializeLenientRawTransaction)
+	if err != nil || value.Extra != nil {
+		t.Fatalf("unexpected extra bytes %x: %v", value.Extra, err)
+	}
+
+	// As if a newer node appended a field to the transaction.
+	input := append(append([]byte(nil), rawBytes...), 0xca, 0xfe)
+	value, err = BcsDeserializeLenient(input, deserializeLenientRawTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(value.Extra, []byte{0xca, 0xfe}) {
+		t.Fatalf("unexpected extra bytes %x", value.Extra)
+	}
+	if txn := value.Value.(*RawTransaction); txn.SequenceNumber != 3 {
+		t.Fatalf("unexpected transaction %v", txn)
+	}
+	if output, err := value.BcsSerialize(); err != nil || !bytes.Equal(output, input) {
+		t.Fatalf("unexpected encoding %x: %v", output, err)
+	}
+	// Through a serializer other than `*Serializer`, which writes the extra bytes one by one.
+	serializer := bcs.NewSerializer()
+	if err := value.Serialize(serializer); err != nil || !bytes.Equal(serializer.GetBytes(), input) {
+		t.Fatalf("unexpected encoding %x: %v", serializer.GetBytes(), err)
+	}
+
+	if _, err := BcsDeserializeLenient(rawBytes[:len(rawBytes)-1], deserializeLenientRawTransaction); err == nil {
+		t.Fatal("accepted a truncated transaction")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import "github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+
+// SerializeMap serializes `m` as a BCS map, i.e. the number of entries followed by the
+// entries in the lexicographic order of the bytes of their serialized keys. The order of
+// the keys as Go values is irrelevant.
+func SerializeMap[K comparable, V any](
+	serializer serde.Serializer,
+	m map[K]V,
+	serializeKey func(serde.Serializer, K) error,
+	serializeValue func(serde.Serializer, V) error,
+) error {
+	if err := serializer.SerializeLen(uint64(len(m))); err != nil {
+		return err
+	}
+	offsets := make([]uint64, 0, len(m))
+	for key, value := range m {
+		offsets = append(offsets, serializer.GetBufferOffset())
+		if err := serializeKey(serializer, key); err != nil {
+			return err
+		}
+		if err := serializeValue(serializer, value); err != nil {
+			return err
+		}
+	}
+	// The encoding of keys is canonical and self-delimiting, so sorting the entries sorts
+	// the keys.
+	serializer.SortMapEntries(offsets)
+	return nil
+}
+
+// DeserializeMap deserializes a BCS map. Like the Rust runtime, it fails unless the keys
+// are serialized in strictly increasing order, which also rules out duplicate keys.
+func DeserializeMap[K comparable, V any](
+	deserializer serde.Deserializer,
+	deserializeKey func(serde.Deserializer) (K, error),
+	deserializeValue func(serde.Deserializer) (V, error),
+) (map[K]V, error) {
+	length, err := deserializer.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[K]V)
+	var previousKey serde.Slice
+	for i := uint64(0); i < length; i++ {
+		keyStart := deserializer.GetBufferOffset()
+		key, err := deserializeKey(deserializer)
+		if err != nil {
+			return nil, err
+		}
+		currentKey := serde.Slice{Start: keyStart, End: deserializer.GetBufferOffset()}
+		if i > 0 {
+			if err := deserializer.CheckThatKeySlicesAreIncreasing(previousKey, currentKey); err != nil {
+				return nil, err
+			}
+		}
+		previousKey = currentKey
+		value, err := deserializeValue(deserializer)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+func serializeU64(serializer serde.Serializer, value uint64) error {
+	return serializer.SerializeU64(value)
+}
+
+func deserializeU64(deserializer serde.Deserializer) (uint64, error) {
+	return deserializer.DeserializeU64()
+}
+
+func serializeBool(serializer serde.Serializer, value bool) error {
+	return serializer.SerializeBool(value)
+}
+
+func deserializeBool(deserializer serde.Deserializer) (bool, error) {
+	return deserializer.DeserializeBool()
+}
+
+func TestSerializeMap(t *testing.T) {
+	// 256 is encoded as 00 01 00 ... and 1 as 01 00 00 ..., so 256 comes first.
+	serializer := NewSerializerWithBuffer(nil)
+	if err := SerializeMap(serializer, map[uint64]bool{1: true, 256: false}, serializeU64, serializeBool); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{2, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}
+	if !bytes.Equal(serializer.GetBytes(), want) {
+		t.Fatalf("unexpected encoding %x", serializer.GetBytes())
+	}
+
+	m, err := DeserializeMap(NewDeserializer(want, DeserializerOptions{}), deserializeU64, deserializeBool)
+	if err != nil || len(m) != 2 || !m[1] || m[256] {
+		t.Fatalf("unexpected map %v: %v", m, err)
+	}
+	outOfOrder := []byte{2, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0}
+	if _, err := DeserializeMap(NewDeserializer(outOfOrder, DeserializerOptions{}), deserializeU64, deserializeBool); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected keys out of order to be rejected, got %v", err)
+	}
+	duplicate := []byte{2, 1, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+	if _, err := DeserializeMap(NewDeserializer(duplicate, DeserializerOptions{}), deserializeU64, deserializeBool); !errors.Is(err, ErrNonCanonical) {
+		t.Fatalf("expected duplicate keys to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// The `metadata` of a payment, e.g. in `peer_to_peer_with_metadata`, is an opaque byte
+// vector whose layout is agreed upon by the sender and the recipient. The types below are
+// common layouts, encoded in BCS like the Move structs:
+//
+//	struct MemoMetadata { memo: String }
+//	struct SenderMemoMetadata { sender: address, memo: String }
+//
+// Their decoders return false instead of an error, so that they can be tried in turn
+// against metadata of unknown layout, see `DecodeMetadata`.
+
+// MetadataDecoder tries to decode metadata of a given layout.
+type MetadataDecoder func(metadata []byte) (interface{}, bool)
+
+// DecodeMetadata returns the value decoded by the first of `decoders` which accepts
+// `metadata`, e.g. `DecodeMetadata(metadata, DecodeSenderMemoMetadata, DecodeMemoMetadata)`.
+// Since BCS does not tag its values, the more specific layouts should come first.
+func DecodeMetadata(metadata []byte, decoders ...MetadataDecoder) (interface{}, bool) {
+	for _, decode := range decoders {
+		if value, ok := decode(metadata); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// MemoMetadata is a free-form UTF-8 note.
+type MemoMetadata struct {
+	Memo string
+}
+
+func (obj *MemoMetadata) Serialize(serializer serde.Serializer) error {
+	return serializer.SerializeStr(obj.Memo)
+}
+
+func (obj *MemoMetadata) BcsSerialize() ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot serialize null object")
+	}
+	return AppendBcs(nil, obj)
+}
+
+// DecodeMemoMetadata decodes `metadata` as a `*MemoMetadata`, if it is one.
+func DecodeMemoMetadata(metadata []byte) (interface{}, bool) {
+	d := NewDeserializer(metadata, DefaultDeserializerOptions)
+	memo, ok := deserializeMemo(d)
+	if !ok || d.Remaining() > 0 {
+		return nil, false
+	}
+	return &MemoMetadata{Memo: memo}, true
+}
+
+// SenderMemoMetadata is a note together with the account on whose behalf a payment is made,
+// e.g. a customer of the sending exchange.
+type SenderMemoMetadata struct {
+	Sender AccountAddress
+	Memo   string
+}
+
+func (obj *SenderMemoMetadata) Serialize(serializer serde.Serializer) error {
+	if err := obj.Sender.Serialize(serializer); err != nil {
+		return err
+	}
+	return serializer.SerializeStr(obj.Memo)
+}
+
+func (obj *SenderMemoMetadata) BcsSerialize() ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot serialize null object")
+	}
+	return AppendBcs(nil, obj)
+}
+
+// DecodeSenderMemoMetadata decodes `metadata` as a `*SenderMemoMetadata`, if it is one.
+func DecodeSenderMemoMetadata(metadata []byte) (interface{}, bool) {
+	d := NewDeserializer(metadata, DefaultDeserializerOptions)
+	sender, err := DeserializeAccountAddress(d)
+	if err != nil {
+		return nil, false
+	}
+	memo, ok := deserializeMemo(d)
+	if !ok || d.Remaining() > 0 {
+		return nil, false
+	}
+	return &SenderMemoMetadata{Sender: sender, Memo: memo}, true
+}
+
+// Read a Move `String`, which must be valid UTF-8.
+func deserializeMemo(d *Deserializer) (string, bool) {
+	memo, err := d.DeserializeStr()
+	if err != nil || !utf8.ValidString(memo) {
+		return "", false
+	}
+	return memo, true
+}
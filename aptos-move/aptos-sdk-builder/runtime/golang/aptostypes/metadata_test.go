@@ -0,0 +1,32 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"testing"
+)
+
+func TestDecodeMetadata(t *testing.T) {
+	expected := SenderMemoMetadata{Sender: AccountAddress{31: 0x22}, Memo: "invoice 42"}
+	metadata, err := expected.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, ok := DecodeMetadata(metadata, DecodeSenderMemoMetadata, DecodeMemoMetadata)
+	if got, isSenderMemo := value.(*SenderMemoMetadata); !ok || !isSenderMemo || *got != expected {
+		t.Fatalf("unexpected metadata %+v", value)
+	}
+
+	if metadata, err = (&MemoMetadata{Memo: "thanks"}).BcsSerialize(); err != nil {
+		t.Fatal(err)
+	}
+	value, ok = DecodeMetadata(metadata, DecodeSenderMemoMetadata, DecodeMemoMetadata)
+	if got, isMemo := value.(*MemoMetadata); !ok || !isMemo || got.Memo != "thanks" {
+		t.Fatalf("unexpected metadata %+v", value)
+	}
+
+	if _, ok := DecodeMetadata([]byte{1, 0xff}, DecodeSenderMemoMetadata, DecodeMemoMetadata); ok {
+		t.Fatal("decoded a memo which is not UTF-8")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MaxMultiEd25519Keys is the maximum number of keys in a k-of-n `MultiEd25519PublicKey`.
+const MaxMultiEd25519Keys = 32
+
+// Length of the `Bitmap4` at the end of a `MultiEd25519Signature`, one bit per key.
+const multiEd25519BitmapLength = len(Bitmap4{})
+
+// NewMultiEd25519PublicKey builds a k-of-n public key with `threshold` = k. The bytes are
+// the n keys concatenated, followed by the threshold byte.
+func NewMultiEd25519PublicKey(keys []ed25519.PublicKey, threshold uint8) (MultiEd25519PublicKey, error) {
+	if len(keys) == 0 || len(keys) > MaxMultiEd25519Keys {
+		return nil, fmt.Errorf("invalid number of multi-ed25519 keys: %d", len(keys))
+	}
+	if threshold == 0 || int(threshold) > len(keys) {
+		return nil, fmt.Errorf("invalid multi-ed25519 threshold %d for %d keys", threshold, len(keys))
+	}
+	b := make([]byte, 0, len(keys)*ed25519.PublicKeySize+1)
+	for _, key := range keys {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key length")
+		}
+		b = append(b, key...)
+	}
+	return MultiEd25519PublicKey(append(b, threshold)), nil
+}
+
+// Keys splits the public key into its individual keys and its threshold.
+func (obj MultiEd25519PublicKey) Keys() ([]ed25519.PublicKey, uint8, error) {
+	n := (len(obj) - 1) / ed25519.PublicKeySize
+	if len(obj) != n*ed25519.PublicKeySize+1 || n == 0 || n > MaxMultiEd25519Keys {
+		return nil, 0, fmt.Errorf("invalid multi-ed25519 public key length: %d", len(obj))
+	}
+	threshold := obj[len(obj)-1]
+	if threshold == 0 || int(threshold) > n {
+		return nil, 0, fmt.Errorf("invalid multi-ed25519 threshold %d for %d keys", threshold, n)
+	}
+	keys := make([]ed25519.PublicKey, n)
+	for i := range keys {
+		keys[i] = ed25519.PublicKey(append([]byte(nil), obj[i*ed25519.PublicKeySize:(i+1)*ed25519.PublicKeySize]...))
+	}
+	return keys, threshold, nil
+}
+
+// AuthKeyFromMultiEd25519 returns the authentication key of a k-of-n account, i.e.
+// `SHA3-256(publicKey || 0x01)`, which is also the address of the account created with it.
+func AuthKeyFromMultiEd25519(publicKey MultiEd25519PublicKey) AccountAddress {
+	return authKey(publicKey, multiEd25519Scheme)
+}
+
+// NewMultiEd25519Signature builds a k-of-n signature from the signatures of some of the
+// keys, indexed by the position of the key in the `MultiEd25519PublicKey`. The bytes are the
+// signatures in increasing key order, followed by a 4-byte bitmap where the most
+// significant bit of the first byte stands for key 0.
+func NewMultiEd25519Signature(signatures map[uint8][]byte) (MultiEd25519Signature, error) {
+	if len(signatures) == 0 {
+		return nil, errors.New("no multi-ed25519 signatures")
+	}
+	indices := make([]int, 0, len(signatures))
+	for index, signature := range signatures {
+		if index >= MaxMultiEd25519Keys {
+			return nil, fmt.Errorf("invalid multi-ed25519 key index: %d", index)
+		}
+		if len(signature) != ed25519.SignatureSize {
+			return nil, errors.New("invalid ed25519 signature length")
+		}
+		indices = append(indices, int(index))
+	}
+	sort.Ints(indices)
+	b := make([]byte, 0, len(signatures)*ed25519.SignatureSize+multiEd25519BitmapLength)
+	var bitmap Bitmap4
+	for _, index := range indices {
+		b = append(b, signatures[uint8(index)]...)
+		bitmap.Set(uint8(index))
+	}
+	return MultiEd25519Signature(append(b, bitmap[:]...)), nil
+}
+
+// Signatures splits the signature into the individual signatures, indexed by key position.
+func (obj MultiEd25519Signature) Signatures() (map[uint8][]byte, error) {
+	if len(obj) < multiEd25519BitmapLength {
+		return nil, fmt.Errorf("invalid multi-ed25519 signature length: %d", len(obj))
+	}
+	var bitmap Bitmap4
+	copy(bitmap[:], obj[len(obj)-multiEd25519BitmapLength:])
+	var indices []uint8
+	for index := uint8(0); index < MaxMultiEd25519Keys; index++ {
+		if bitmap.IsSet(index) {
+			indices = append(indices, index)
+		}
+	}
+	if len(indices) == 0 || len(obj) != len(indices)*ed25519.SignatureSize+multiEd25519BitmapLength {
+		return nil, fmt.Errorf("invalid multi-ed25519 signature length %d for %d signers", len(obj), len(indices))
+	}
+	signatures := make(map[uint8][]byte, len(indices))
+	for i, index := range indices {
+		signatures[index] = append([]byte(nil), obj[i*ed25519.SignatureSize:(i+1)*ed25519.SignatureSize]...)
+	}
+	return signatures, nil
+}
+
+// Check a k-of-n signature of `message`: it must hold at least k signatures, which must all
+// be valid.
+func verifyMultiEd25519(publicKey MultiEd25519PublicKey, signature MultiEd25519Signature, message []byte) (bool, error) {
+	keys, threshold, err := publicKey.Keys()
+	if err != nil {
+		return false, err
+	}
+	signatures, err := signature.Signatures()
+	if err != nil {
+		return false, err
+	}
+	if len(signatures) < int(threshold) {
+		return false, nil
+	}
+	for index, signature := range signatures {
+		if int(index) >= len(keys) {
+			return false, fmt.Errorf("invalid multi-ed25519 key index: %d", index)
+		}
+		if !ed25519.Verify(keys[index], message, signature) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// SignMultiEd25519 signs the transaction with the private keys of some of the keys of
+// `publicKey`, indexed by key position, and wraps the result in a MultiEd25519
+// `TransactionAuthenticator`. At least `threshold` private keys must be given.
+func (obj *RawTransaction) SignMultiEd25519(
+	publicKey MultiEd25519PublicKey,
+	privKeys map[uint8]ed25519.PrivateKey,
+	options ...HashOption,
+) (*SignedTransaction, error) {
+	keys, threshold, err := publicKey.Keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(privKeys) < int(threshold) {
+		return nil, fmt.Errorf("got %d private keys but the threshold is %d", len(privKeys), threshold)
+	}
+	message, err := obj.SigningMessage(options...)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make(map[uint8][]byte, len(privKeys))
+	for index, privKey := range privKeys {
+		if len(privKey) != ed25519.PrivateKeySize {
+			return nil, errors.New("invalid ed25519 private key length")
+		}
+		if int(index) >= len(keys) || !Ed25519PublicKey(keys[index]).ConstantTimeEqual(Ed25519PublicKey(privKey.Public().(ed25519.PublicKey))) {
+			return nil, fmt.Errorf("private key %d does not match the multi-ed25519 public key", index)
+		}
+		signatures[index] = ed25519.Sign(privKey, message)
+	}
+	signature, err := NewMultiEd25519Signature(signatures)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTransaction{
+		RawTxn: *obj,
+		Authenticator: &TransactionAuthenticator__MultiEd25519{
+			PublicKey: publicKey,
+			Signature: signature,
+		},
+	}, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+)
+
+func TestNewMultiEd25519Signature(t *testing.T) {
+	signatures := map[uint8][]byte{
+		31: bytes.Repeat([]byte{31}, ed25519.SignatureSize),
+		0:  bytes.Repeat([]byte{0}, ed25519.SignatureSize),
+		9:  bytes.Repeat([]byte{9}, ed25519.SignatureSize),
+	}
+	signature, err := NewMultiEd25519Signature(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The signatures in key order, then the bitmap with key 0 as the most significant bit
+	// of its first byte.
+	want := append(append(append([]byte(nil), signatures[0]...), signatures[9]...), signatures[31]...)
+	want = append(want, 0x80, 0x40, 0x00, 0x01)
+	if !bytes.Equal(signature, want) {
+		t.Fatalf("unexpected signature layout %x", signature)
+	}
+	if decoded, err := signature.Signatures(); err != nil || !reflect.DeepEqual(decoded, signatures) {
+		t.Fatalf("failed to split the signature: %v", err)
+	}
+
+	if _, err := NewMultiEd25519Signature(map[uint8][]byte{32: signatures[0]}); err == nil {
+		t.Fatal("accepted the key index 32")
+	}
+	if _, err := NewMultiEd25519Signature(map[uint8][]byte{0: signatures[0][1:]}); err == nil {
+		t.Fatal("accepted a short signature")
+	}
+	if _, err := signature[1:].Signatures(); err == nil {
+		t.Fatal("accepted a signature of the wrong length for its bitmap")
+	}
+}
+
+func TestSignMultiEd25519(t *testing.T) {
+	var privKeys []ed25519.PrivateKey
+	var pubKeys []ed25519.PublicKey
+	for i := byte(1); i <= 3; i++ {
+		privKey, pubKey := testKeyPair([32]byte{i})
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys, pubKey)
+	}
+	publicKey, err := NewMultiEd25519PublicKey(pubKeys, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append(append([]byte(nil), pubKeys[0]...), pubKeys[1]...), pubKeys[2]...)
+	if !bytes.Equal(publicKey, append(want, 2)) {
+		t.Fatalf("unexpected public key layout %x", publicKey)
+	}
+
+	raw := RawTransaction{SequenceNumber: 1, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	signed, err := raw.SignMultiEd25519(publicKey, map[uint8]ed25519.PrivateKey{0: privKeys[0], 2: privKeys[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := signed.VerifyEd25519(); !ok || err != nil {
+		t.Fatalf("failed to verify a 2-of-3 signature: %v", err)
+	}
+	message, err := raw.SigningMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := signed.Authenticator.(*TransactionAuthenticator__MultiEd25519).Signature
+	want = append(ed25519.Sign(privKeys[0], message), ed25519.Sign(privKeys[2], message)...)
+	if !bytes.Equal(signature, append(want, 0xa0, 0, 0, 0)) {
+		t.Fatalf("unexpected signature %x", signature)
+	}
+
+	// Below the threshold.
+	single, err := NewMultiEd25519Signature(map[uint8][]byte{0: signature[:ed25519.SignatureSize]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.Authenticator = &TransactionAuthenticator__MultiEd25519{PublicKey: publicKey, Signature: single}
+	if ok, err := signed.VerifyEd25519(); ok || err != nil {
+		t.Fatalf("verified a 1-of-3 signature: %v", err)
+	}
+	// A tampered signature.
+	tampered := append(MultiEd25519Signature(nil), signature...)
+	tampered[ed25519.SignatureSize] ^= 1
+	signed.Authenticator = &TransactionAuthenticator__MultiEd25519{PublicKey: publicKey, Signature: tampered}
+	if ok, err := signed.VerifyEd25519(); ok || err != nil {
+		t.Fatalf("verified a tampered signature: %v", err)
+	}
+
+	if _, err := raw.SignMultiEd25519(publicKey, map[uint8]ed25519.PrivateKey{0: privKeys[0]}); err == nil {
+		t.Fatal("signed with fewer keys than the threshold")
+	}
+	if _, err := raw.SignMultiEd25519(publicKey, map[uint8]ed25519.PrivateKey{0: privKeys[0], 1: privKeys[2]}); err == nil {
+		t.Fatal("signed with a key at the wrong index")
+	}
+}
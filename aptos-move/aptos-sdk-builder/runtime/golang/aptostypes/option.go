@@ -0,0 +1,42 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import "github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+
+// SerializeOption serializes an optional value, represented by a possibly nil pointer as
+// in the generated code: `0x00` for none, `0x01` followed by the value for some. This is
+// also the encoding of a Move `Option<T>`, i.e. a vector of length 0 or 1.
+func SerializeOption[T any](
+	serializer serde.Serializer,
+	value *T,
+	serializeValue func(serde.Serializer, T) error,
+) error {
+	if err := serializer.SerializeOptionTag(value != nil); err != nil {
+		return err
+	}
+	if value == nil {
+		return nil
+	}
+	return serializeValue(serializer, *value)
+}
+
+// DeserializeOption deserializes an optional value written by `SerializeOption`. Tags other
+// than `0x00` and `0x01` are rejected.
+func DeserializeOption[T any](
+	deserializer serde.Deserializer,
+	deserializeValue func(serde.Deserializer) (T, error),
+) (*T, error) {
+	tag, err := deserializer.DeserializeOptionTag()
+	if err != nil || !tag {
+		return nil, err
+	}
+	value, err := deserializeValue(deserializer)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSerializeOption(t *testing.T) {
+	serializer := NewSerializerWithBuffer(nil)
+	if err := SerializeOption[uint64](serializer, nil, serializeU64); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(serializer.GetBytes(), []byte{0}) {
+		t.Fatalf("unexpected encoding of none %x", serializer.GetBytes())
+	}
+	value := uint64(7)
+	if err := SerializeOption(serializer, &value, serializeU64); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0, 1, 7, 0, 0, 0, 0, 0, 0, 0}
+	if !bytes.Equal(serializer.GetBytes(), want) {
+		t.Fatalf("unexpected encoding of some %x", serializer.GetBytes())
+	}
+
+	d := NewDeserializer(want, DeserializerOptions{})
+	if none, err := DeserializeOption(d, deserializeU64); none != nil || err != nil {
+		t.Fatalf("failed to decode none: %v", err)
+	}
+	if some, err := DeserializeOption(d, deserializeU64); some == nil || *some != 7 || err != nil || d.Remaining() != 0 {
+		t.Fatalf("failed to decode some: %v", err)
+	}
+	if _, err := DeserializeOption(NewDeserializer([]byte{2, 7, 0, 0, 0, 0, 0, 0, 0}, DeserializerOptions{}), deserializeU64); !errors.Is(err, ErrMalformedInput) {
+		t.Fatalf("expected the tag 2 to be rejected, got %v", err)
+	}
+}
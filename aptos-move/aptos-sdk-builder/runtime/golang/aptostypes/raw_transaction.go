@@ -0,0 +1,81 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Validate checks the fields of the transaction that would otherwise be rejected by the
+// node with a VM status, using the current time. See `ValidateAt`.
+func (obj *RawTransaction) Validate() error {
+	return obj.ValidateAt(time.Now())
+}
+
+// ValidateAt checks that the transaction has a payload, that its maximum gas amount and gas
+// unit price are not zero, and that it does not expire at or before `now`. Passing a fixed
+// time makes the result deterministic.
+func (obj *RawTransaction) ValidateAt(now time.Time) error {
+	if obj.Payload == nil {
+		return errors.New("invalid raw transaction: missing payload")
+	}
+	if obj.MaxGasAmount == 0 {
+		return errors.New("invalid raw transaction: max gas amount is zero")
+	}
+	if obj.GasUnitPrice == 0 {
+		return errors.New("invalid raw transaction: gas unit price is zero")
+	}
+	// The prologue requires the expiration to be strictly after the current block time.
+	if seconds := now.Unix(); seconds >= 0 && obj.ExpirationTimestampSecs <= uint64(seconds) {
+		return fmt.Errorf(
+			"invalid raw transaction: expiration %s is not after %s",
+			time.Unix(int64(obj.ExpirationTimestampSecs), 0).UTC().Format(time.RFC3339),
+			now.UTC().Format(time.RFC3339),
+		)
+	}
+	return nil
+}
+
+// AccountSequenceInfo is the state of the sender that a transaction depends on, as returned
+// by the REST API of a node: the sequence number of `GET /accounts/{address}` and the chain ID
+// of the ledger information. Fetching it is left to the caller.
+type AccountSequenceInfo struct {
+	Address        AccountAddress
+	SequenceNumber uint64
+	ChainId        ChainId
+}
+
+// FromAccountInfo sets the sender, the sequence number and the chain ID of the transaction
+// and returns it, e.g.
+//
+//	txn := (&aptostypes.RawTransaction{
+//		Payload:      payload,
+//		MaxGasAmount: 2_000,
+//		GasUnitPrice: 1,
+//	}).FromAccountInfo(info).WithExpirationFromNow(time.Minute)
+func (obj *RawTransaction) FromAccountInfo(info AccountSequenceInfo) *RawTransaction {
+	obj.Sender = info.Address
+	obj.SequenceNumber = info.SequenceNumber
+	obj.ChainId = info.ChainId
+	return obj
+}
+
+// WithExpirationFromNow makes the transaction expire `d` after the current time and returns
+// it. See `WithExpirationFrom`.
+func (obj *RawTransaction) WithExpirationFromNow(d time.Duration) *RawTransaction {
+	return obj.WithExpirationFrom(time.Now(), d)
+}
+
+// WithExpirationFrom makes the transaction expire `d` after `now` and returns it. The
+// expiration is in seconds, rounded down. Passing a fixed time makes the result
+// deterministic.
+func (obj *RawTransaction) WithExpirationFrom(now time.Time, d time.Duration) *RawTransaction {
+	obj.ExpirationTimestampSecs = 0
+	if seconds := now.Add(d).Unix(); seconds > 0 {
+		obj.ExpirationTimestampSecs = uint64(seconds)
+	}
+	return obj
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRawTransactionValidateAt(t *testing.T) {
+	now := time.Unix(1000, 0)
+	valid := RawTransaction{Payload: &TransactionPayload__ScriptFunction{}, MaxGasAmount: 1, GasUnitPrice: 1, ExpirationTimestampSecs: 1001}
+	if err := valid.ValidateAt(now); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		change func(*RawTransaction)
+		msg    string
+	}{
+		"missing payload":    {func(raw *RawTransaction) { raw.Payload = nil }, "missing payload"},
+		"no gas":             {func(raw *RawTransaction) { raw.MaxGasAmount = 0 }, "max gas amount is zero"},
+		"free gas":           {func(raw *RawTransaction) { raw.GasUnitPrice = 0 }, "gas unit price is zero"},
+		"expiring now":       {func(raw *RawTransaction) { raw.ExpirationTimestampSecs = 1000 }, "is not after 1970-01-01T00:16:40Z"},
+		"expired":            {func(raw *RawTransaction) { raw.ExpirationTimestampSecs = 1 }, "is not after"},
+		"without expiration": {func(raw *RawTransaction) { raw.ExpirationTimestampSecs = 0 }, "is not after"},
+	} {
+		invalid := valid
+		test.change(&invalid)
+		if err := invalid.ValidateAt(now); err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, test.msg, err)
+		}
+	}
+
+	soon := valid.WithExpirationFromNow(time.Minute)
+	if err := soon.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithExpirationFrom(t *testing.T) {
+	now := time.Unix(1_660_000_000, 999_000_000)
+	var raw RawTransaction
+	if raw.WithExpirationFrom(now, time.Minute) != &raw {
+		t.Fatal("WithExpirationFrom should return its receiver")
+	}
+	// Rounded down to the second.
+	if raw.ExpirationTimestampSecs != 1_660_000_060 {
+		t.Fatalf("expected an expiration of 1660000060, got %d", raw.ExpirationTimestampSecs)
+	}
+	if raw.WithExpirationFrom(now, 1500*time.Millisecond).ExpirationTimestampSecs != 1_660_000_002 {
+		t.Fatalf("expected an expiration of 1660000002, got %d", raw.ExpirationTimestampSecs)
+	}
+	// Before the epoch.
+	if raw.WithExpirationFrom(time.Unix(0, 0), -time.Minute).ExpirationTimestampSecs != 0 {
+		t.Fatalf("expected an expiration of 0, got %d", raw.ExpirationTimestampSecs)
+	}
+
+	before := uint64(time.Now().Add(time.Minute).Unix())
+	raw.WithExpirationFromNow(time.Minute)
+	if after := uint64(time.Now().Add(time.Minute).Unix()); raw.ExpirationTimestampSecs < before || raw.ExpirationTimestampSecs > after {
+		t.Fatalf("expected an expiration between %d and %d, got %d", before, after, raw.ExpirationTimestampSecs)
+	}
+}
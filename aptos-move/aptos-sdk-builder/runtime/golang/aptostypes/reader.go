@@ -0,0 +1,73 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import "io"
+
+// BcsDeserializeSignedTransactionFrom decodes one signed transaction from `r` and returns
+// it together with the number of bytes consumed. See `NewDeserializerFromReader` for other
+// types.
+func BcsDeserializeSignedTransactionFrom(r io.Reader) (SignedTransaction, int, error) {
+	d := NewDeserializerFromReader(r, DefaultDeserializerOptions)
+	obj, err := DeserializeSignedTransaction(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeRawTransactionFrom decodes one raw transaction from `r` and returns it
+// together with the number of bytes consumed.
+func BcsDeserializeRawTransactionFrom(r io.Reader) (RawTransaction, int, error) {
+	d := NewDeserializerFromReader(r, DefaultDeserializerOptions)
+	obj, err := DeserializeRawTransaction(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeTransactionPayloadFrom decodes one transaction payload from `r` and returns
+// it together with the number of bytes consumed.
+func BcsDeserializeTransactionPayloadFrom(r io.Reader) (TransactionPayload, int, error) {
+	d := NewDeserializerFromReader(r, DefaultDeserializerOptions)
+	obj, err := DeserializeTransactionPayload(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeTransactionFrom decodes one transaction of any kind, e.g. from a stream of
+// committed transactions, and returns it together with the number of bytes consumed.
+// Variants unknown to this package are rejected with an error.
+func BcsDeserializeTransactionFrom(r io.Reader) (Transaction, int, error) {
+	d := NewDeserializerFromReader(r, DefaultDeserializerOptions)
+	obj, err := DeserializeTransaction(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeSignedTransactionPrefix decodes the signed transaction at the start of `input`
+// and returns it together with the number of bytes consumed. Unlike
+// `BcsDeserializeSignedTransaction`, it accepts input bytes beyond the end of the value.
+func BcsDeserializeSignedTransactionPrefix(input []byte) (SignedTransaction, int, error) {
+	d := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeSignedTransaction(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeRawTransactionPrefix decodes the raw transaction at the start of `input` and
+// returns it together with the number of bytes consumed.
+func BcsDeserializeRawTransactionPrefix(input []byte) (RawTransaction, int, error) {
+	d := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeRawTransaction(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeTransactionPayloadPrefix decodes the transaction payload at the start of
+// `input` and returns it together with the number of bytes consumed.
+func BcsDeserializeTransactionPayloadPrefix(input []byte) (TransactionPayload, int, error) {
+	d := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeTransactionPayload(d)
+	return obj, int(d.GetBufferOffset()), err
+}
+
+// BcsDeserializeTransactionPrefix decodes the transaction of any kind at the start of `input`
+// and returns it together with the number of bytes consumed.
+func BcsDeserializeTransactionPrefix(input []byte) (Transaction, int, error) {
+	d := NewDeserializer(input, DefaultDeserializerOptions)
+	obj, err := DeserializeTransaction(d)
+	return obj, int(d.GetBufferOffset()), err
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestBcsDeserializeSignedTransactionFrom(t *testing.T) {
+	privKey, _ := testKeyPair([32]byte{1})
+	// An argument larger than the chunks read from the stream.
+	raw := RawTransaction{ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{Value: ScriptFunction{
+		Module:   ModuleId{Address: CoreCodeAddress, Name: "code"},
+		Function: "publish",
+		Args:     [][]byte{bytes.Repeat([]byte{7}, 2*readChunkSize+1)},
+	}}}
+	signed, err := raw.SignEd25519(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := signed.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := bytes.NewReader(append(append([]byte(nil), input...), input...))
+	for i := 0; i < 2; i++ {
+		decoded, n, err := BcsDeserializeSignedTransactionFrom(stream)
+		if err != nil || n != len(input) {
+			t.Fatalf("transaction %d: read %d bytes out of %d: %v", i, n, len(input), err)
+		}
+		if output, err := decoded.BcsSerialize(); err != nil || !bytes.Equal(output, input) {
+			t.Fatalf("transaction %d: decoded a different transaction", i)
+		}
+	}
+	// A clean end of stream, then one in the middle of a transaction.
+	if _, n, err := BcsDeserializeSignedTransactionFrom(stream); err != io.EOF || n != 0 {
+		t.Fatalf("expected io.EOF, got %d bytes and %v", n, err)
+	}
+	if _, _, err := BcsDeserializeSignedTransactionFrom(bytes.NewReader(input[:len(input)-1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	rawInput, err := raw.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, n, err := BcsDeserializeRawTransactionFrom(bytes.NewReader(rawInput)); err != nil || n != len(rawInput) {
+		t.Fatalf("read %d bytes out of %d: %v", n, len(rawInput), err)
+	}
+}
+
+func TestBcsDeserializeTransactionPayloadPrefix(t *testing.T) {
+	input, err := coinTransferPayload().BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trailing := append(append([]byte(nil), input...), 0xff)
+	if _, err := BcsDeserializeTransactionPayload(trailing); err == nil {
+		t.Fatal("BcsDeserializeTransactionPayload accepted a trailing byte")
+	}
+	if _, n, err := BcsDeserializeTransactionPayloadPrefix(trailing); err != nil || n != len(input) {
+		t.Fatalf("read %d bytes out of %d: %v", n, len(input), err)
+	}
+	if _, _, err := BcsDeserializeTransactionPayloadPrefix(input[:len(input)-1]); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+
+	signed := SignedTransaction{RawTxn: RawTransaction{Payload: coinTransferPayload()}, Authenticator: &TransactionAuthenticator__Ed25519{}}
+	if input, err = signed.BcsSerialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, n, err := BcsDeserializeSignedTransactionPrefix(append(input, input...)); err != nil || n != len(input) {
+		t.Fatalf("read %d bytes out of %d: %v", n, len(input), err)
+	}
+	rawInput, err := signed.RawTxn.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The raw transaction at the start of the signed one.
+	if _, n, err := BcsDeserializeRawTransactionPrefix(input); err != nil || n != len(rawInput) {
+		t.Fatalf("read %d bytes out of %d: %v", n, len(rawInput), err)
+	}
+}
+
+func TestBcsDeserializeTransactionFrom(t *testing.T) {
+	var input []byte
+	var lengths []int
+	for _, txn := range []Transaction{
+		&Transaction__UserTransaction{Value: SignedTransaction{
+			RawTxn:        RawTransaction{Payload: coinTransferPayload()},
+			Authenticator: &TransactionAuthenticator__Ed25519{},
+		}},
+		&Transaction__StateCheckpoint{},
+	} {
+		encoded, err := txn.BcsSerialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		input = append(input, encoded...)
+		lengths = append(lengths, len(encoded))
+	}
+
+	stream := bytes.NewReader(input)
+	if txn, n, err := BcsDeserializeTransactionFrom(stream); err != nil || n != lengths[0] {
+		t.Fatalf("read %d bytes out of %d: %v", n, lengths[0], err)
+	} else if _, ok := txn.(*Transaction__UserTransaction); !ok {
+		t.Fatalf("expected a user transaction, got %T", txn)
+	}
+	if txn, n, err := BcsDeserializeTransactionFrom(stream); err != nil || n != lengths[1] {
+		t.Fatalf("read %d bytes out of %d: %v", n, lengths[1], err)
+	} else if _, ok := txn.(*Transaction__StateCheckpoint); !ok {
+		t.Fatalf("expected a state checkpoint, got %T", txn)
+	}
+	if _, _, err := BcsDeserializeTransactionFrom(stream); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	if _, n, err := BcsDeserializeTransactionPrefix(input); err != nil || n != lengths[0] {
+		t.Fatalf("read %d bytes out of %d: %v", n, lengths[0], err)
+	}
+	if _, _, err := BcsDeserializeTransactionPrefix([]byte{99}); !errors.Is(err, ErrUnknownVariant) {
+		t.Fatalf("expected an unknown variant, got %v", err)
+	}
+}
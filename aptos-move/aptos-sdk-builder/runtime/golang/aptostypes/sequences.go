@@ -0,0 +1,56 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import "github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+
+// Upper bound on the number of elements preallocated by `DeserializeVector`, so that a
+// forged length does not allocate more memory than the input could fill.
+const maxPreallocatedElements = 1 << 16
+
+// SerializeVector serializes `values` as a BCS sequence: the number of elements followed by
+// the elements in order.
+func SerializeVector[T any](
+	serializer serde.Serializer,
+	values []T,
+	serializeValue func(serde.Serializer, T) error,
+) error {
+	if err := serializer.SerializeLen(uint64(len(values))); err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := serializeValue(serializer, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeserializeVector deserializes a sequence written by `SerializeVector`. The length is
+// read and checked against the limits of the deserializer once, and the result is
+// preallocated, which is faster than appending the elements of a large vector one by one.
+func DeserializeVector[T any](
+	deserializer serde.Deserializer,
+	deserializeValue func(serde.Deserializer) (T, error),
+) ([]T, error) {
+	length, err := deserializer.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	capacity := length
+	if capacity > maxPreallocatedElements {
+		capacity = maxPreallocatedElements
+	}
+	values := make([]T, 0, capacity)
+	for i := uint64(0); i < length; i++ {
+		value, err := deserializeValue(deserializer)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+func TestSerializeVector(t *testing.T) {
+	serializer := NewSerializerWithBuffer(nil)
+	if err := SerializeVector(serializer, []uint64{1, 256}, serializeU64); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{2, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}
+	if !bytes.Equal(serializer.GetBytes(), want) {
+		t.Fatalf("unexpected encoding %x", serializer.GetBytes())
+	}
+	values, err := DeserializeVector(NewDeserializer(want, DeserializerOptions{}), deserializeU64)
+	if err != nil || len(values) != 2 || values[0] != 1 || values[1] != 256 {
+		t.Fatalf("unexpected values %v: %v", values, err)
+	}
+	if _, err := DeserializeVector(NewDeserializer(want[:len(want)-1], DeserializerOptions{}), deserializeU64); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected a truncated input, got %v", err)
+	}
+
+	// A forged length read from a stream, which cannot be checked against the input left,
+	// must not preallocate 2^31-1 elements.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	d := NewDeserializerFromReader(bytes.NewReader(hugeLengthInput), DeserializerOptions{})
+	if _, err := DeserializeVector(d, deserializeU64); err == nil {
+		t.Fatal("accepted a truncated input")
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8*maxPreallocatedElements+1<<20 {
+		t.Fatalf("rejecting the input allocated %d bytes", allocated)
+	}
+}
+
+// Compare the preallocated result of `DeserializeVector` with appending 10k elements one
+// by one:
+//
+//	go test -run NONE -bench DeserializeVector
+func BenchmarkDeserializeVector(b *testing.B) {
+	values := make([]uint64, 10000)
+	for i := range values {
+		values[i] = uint64(i)
+	}
+	serializer := NewSerializerWithBuffer(nil)
+	if err := SerializeVector(serializer, values, serializeU64); err != nil {
+		b.Fatal(err)
+	}
+	input := serializer.GetBytes()
+	b.Run("preallocated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DeserializeVector(NewDeserializer(input, DeserializerOptions{}), deserializeU64); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("appending", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := appendVector(NewDeserializer(input, DeserializerOptions{}), deserializeU64); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// The loop of `DeserializeVector` without preallocation.
+func appendVector[T any](deserializer serde.Deserializer, deserializeValue func(serde.Deserializer) (T, error)) ([]T, error) {
+	length, err := deserializer.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	var values []T
+	for i := uint64(0); i < length; i++ {
+		value, err := deserializeValue(deserializer)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
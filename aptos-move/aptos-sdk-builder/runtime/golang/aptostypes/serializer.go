@@ -0,0 +1,241 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// Serializable is implemented by all the generated Aptos types.
+type Serializable interface {
+	Serialize(serializer serde.Serializer) error
+}
+
+// Serializer is a BCS implementation of `serde.Serializer` appending to a caller-provided
+// buffer. Unlike `bcs.NewSerializer`, it lets high-throughput callers reuse memory.
+type Serializer struct {
+	buf   []byte
+	depth uint64
+}
+
+var _ serde.Serializer = (*Serializer)(nil)
+
+// NewSerializerWithBuffer creates a serializer appending to `buf[:0]`, so that the capacity
+// of `buf` is reused.
+func NewSerializerWithBuffer(buf []byte) *Serializer {
+	return &Serializer{buf: buf[:0]}
+}
+
+// NewSerializerWithCapacity creates a serializer whose buffer can hold `n` bytes before
+// growing, e.g. the size of the modules of a large package, as given by
+// `BcsSerializedLength`.
+func NewSerializerWithCapacity(n int) *Serializer {
+	return &Serializer{buf: make([]byte, 0, n)}
+}
+
+var serializerPool = sync.Pool{
+	New: func() interface{} { return new(Serializer) },
+}
+
+// AppendBcs appends the BCS encoding of `value` to `dst` and returns the extended buffer.
+// Passing the same buffer (truncated to zero length) on every call avoids allocations in
+// steady state:
+//
+//	buf := make([]byte, 0, 1024)
+//	for _, payload := range payloads {
+//		buf, err = aptostypes.AppendBcs(buf[:0], payload)
+//		...
+//	}
+func AppendBcs(dst []byte, value Serializable) ([]byte, error) {
+	s := serializerPool.Get().(*Serializer)
+	s.buf, s.depth = dst, 0
+	err := value.Serialize(s)
+	out := s.buf
+	s.buf = nil
+	serializerPool.Put(s)
+	return out, err
+}
+
+func (s *Serializer) IncreaseContainerDepth() error {
+	if s.depth >= bcs.MaxContainerDepth {
+		return errors.New("max container depth exceeded")
+	}
+	s.depth++
+	return nil
+}
+
+func (s *Serializer) DecreaseContainerDepth() {
+	s.depth--
+}
+
+func (s *Serializer) GetBufferOffset() uint64 {
+	return uint64(len(s.buf))
+}
+
+func (s *Serializer) GetBytes() []byte {
+	return s.buf
+}
+
+// Bytes returns the bytes written so far, e.g. to inspect a partially serialized value.
+// The slice aliases the internal buffer: callers must not modify it, and it is only valid
+// until the next write or `Reset`.
+func (s *Serializer) Bytes() []byte {
+	return s.buf
+}
+
+// Reset discards the bytes written so far but keeps the capacity of the buffer, so that the
+// serializer can be reused for another value.
+func (s *Serializer) Reset() {
+	s.buf, s.depth = s.buf[:0], 0
+}
+
+func (s *Serializer) SerializeStr(value string) error {
+	if err := s.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	s.buf = append(s.buf, value...)
+	return nil
+}
+
+func (s *Serializer) SerializeBytes(value []byte) error {
+	if err := s.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	s.buf = append(s.buf, value...)
+	return nil
+}
+
+func (s *Serializer) SerializeBool(value bool) error {
+	if value {
+		return s.SerializeU8(1)
+	}
+	return s.SerializeU8(0)
+}
+
+func (s *Serializer) SerializeUnit(value struct{}) error {
+	return nil
+}
+
+func (s *Serializer) SerializeChar(value rune) error {
+	return errors.New("BCS does not support char")
+}
+
+func (s *Serializer) SerializeF32(value float32) error {
+	return errors.New("BCS does not support f32")
+}
+
+func (s *Serializer) SerializeF64(value float64) error {
+	return errors.New("BCS does not support f64")
+}
+
+func (s *Serializer) SerializeU8(value uint8) error {
+	s.buf = append(s.buf, value)
+	return nil
+}
+
+// BCS integers are little-endian whatever the byte order of the host. They are written with
+// `binary.LittleEndian`, never by reinterpreting memory, and 128-bit values as two 64-bit
+// halves, the low one first.
+
+func (s *Serializer) SerializeU16(value uint16) error {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], value)
+	s.buf = append(s.buf, b[:]...)
+	return nil
+}
+
+func (s *Serializer) SerializeU32(value uint32) error {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], value)
+	s.buf = append(s.buf, b[:]...)
+	return nil
+}
+
+func (s *Serializer) SerializeU64(value uint64) error {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], value)
+	s.buf = append(s.buf, b[:]...)
+	return nil
+}
+
+func (s *Serializer) SerializeU128(value serde.Uint128) error {
+	s.SerializeU64(value.Low)
+	return s.SerializeU64(value.High)
+}
+
+func (s *Serializer) SerializeI8(value int8) error {
+	return s.SerializeU8(uint8(value))
+}
+
+func (s *Serializer) SerializeI16(value int16) error {
+	return s.SerializeU16(uint16(value))
+}
+
+func (s *Serializer) SerializeI32(value int32) error {
+	return s.SerializeU32(uint32(value))
+}
+
+func (s *Serializer) SerializeI64(value int64) error {
+	return s.SerializeU64(uint64(value))
+}
+
+func (s *Serializer) SerializeI128(value serde.Int128) error {
+	s.SerializeU64(value.Low)
+	return s.SerializeI64(value.High)
+}
+
+func (s *Serializer) serializeU32AsUleb128(value uint32) {
+	for value >= 0x80 {
+		s.buf = append(s.buf, byte(value&0x7f)|0x80)
+		value >>= 7
+	}
+	s.buf = append(s.buf, byte(value))
+}
+
+func (s *Serializer) SerializeLen(value uint64) error {
+	if value > bcs.MaxSequenceLength {
+		return errors.New("length is too large")
+	}
+	s.serializeU32AsUleb128(uint32(value))
+	return nil
+}
+
+func (s *Serializer) SerializeVariantIndex(value uint32) error {
+	s.serializeU32AsUleb128(value)
+	return nil
+}
+
+func (s *Serializer) SerializeOptionTag(value bool) error {
+	return s.SerializeBool(value)
+}
+
+// Sort the map entries starting at the given offsets by their serialized bytes.
+func (s *Serializer) SortMapEntries(offsets []uint64) {
+	if len(offsets) <= 1 {
+		return
+	}
+	start := offsets[0]
+	entries := make([][]byte, len(offsets))
+	for i, offset := range offsets {
+		end := uint64(len(s.buf))
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		entries[i] = append([]byte(nil), s.buf[offset:end]...)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i], entries[j]) < 0
+	})
+	s.buf = s.buf[:start]
+	for _, entry := range entries {
+		s.buf = append(s.buf, entry...)
+	}
+}
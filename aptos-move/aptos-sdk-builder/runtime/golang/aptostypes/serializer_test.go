@@ -0,0 +1,113 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+func coinTransferPayload() TransactionPayload {
+	return &TransactionPayload__ScriptFunction{Value: ScriptFunction{
+		Module:   ModuleId{Address: CoreCodeAddress, Name: "coin"},
+		Function: "transfer",
+		TyArgs:   []TypeTag{StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")},
+		Args:     [][]byte{make([]byte, 32), {5, 0, 0, 0, 0, 0, 0, 0}},
+	}}
+}
+
+func TestAppendBcs(t *testing.T) {
+	payload := coinTransferPayload()
+	want, err := payload.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Starting from a buffer too small, which AppendBcs grows.
+	buf := make([]byte, 0, 8)
+	for i := 0; i < 3; i++ {
+		if buf, err = AppendBcs(buf[:0], payload); err != nil || !bytes.Equal(buf, want) {
+			t.Fatalf("unexpected encoding %x: %v", buf, err)
+		}
+	}
+	if prefixed, err := AppendBcs([]byte{0xca, 0xfe}, payload); err != nil || !bytes.Equal(prefixed, append([]byte{0xca, 0xfe}, want...)) {
+		t.Fatalf("failed to append to a non-empty buffer: %v", err)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { buf, _ = AppendBcs(buf[:0], payload) }); allocs > 0 {
+		t.Fatalf("reusing the buffer took %v allocations", allocs)
+	}
+}
+
+func TestSerializerLittleEndian(t *testing.T) {
+	u128 := serde.Uint128{High: 0x0102030405060708, Low: 0x090a0b0c0d0e0f10}
+	value, _ := new(big.Int).SetString("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20", 16)
+	u256, err := NewU256(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializer := NewSerializerWithBuffer(nil)
+	serializer.SerializeU16(0x0102)
+	serializer.SerializeU32(0x01020304)
+	serializer.SerializeU64(0x0102030405060708)
+	serializer.SerializeU128(u128)
+	u256.Serialize(serializer)
+
+	want := []byte{
+		2, 1,
+		4, 3, 2, 1,
+		8, 7, 6, 5, 4, 3, 2, 1,
+		0x10, 0xf, 0xe, 0xd, 0xc, 0xb, 0xa, 9, 8, 7, 6, 5, 4, 3, 2, 1,
+	}
+	for i := 0x20; i > 0; i-- {
+		want = append(want, byte(i))
+	}
+	if !bytes.Equal(serializer.GetBytes(), want) {
+		t.Fatalf("unexpected encoding %x", serializer.GetBytes())
+	}
+
+	d := NewDeserializer(want, DeserializerOptions{})
+	if value, err := d.DeserializeU16(); err != nil || value != 0x0102 {
+		t.Fatalf("unexpected u16 %#x: %v", value, err)
+	}
+	if value, err := d.DeserializeU32(); err != nil || value != 0x01020304 {
+		t.Fatalf("unexpected u32 %#x: %v", value, err)
+	}
+	if value, err := d.DeserializeU64(); err != nil || value != 0x0102030405060708 {
+		t.Fatalf("unexpected u64 %#x: %v", value, err)
+	}
+	if value, err := d.DeserializeU128(); err != nil || value != u128 {
+		t.Fatalf("unexpected u128 %v: %v", value, err)
+	}
+	if value, err := DeserializeU256(d); err != nil || value != u256 || d.Remaining() != 0 {
+		t.Fatalf("unexpected u256 %v: %v", value, err)
+	}
+}
+
+// Compare the allocations of AppendBcs with a reused buffer and of BcsSerialize:
+//
+//	go test -run NONE -bench Serialize
+func BenchmarkSerialize(b *testing.B) {
+	payload := coinTransferPayload()
+	b.Run("AppendBcs", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			var err error
+			if buf, err = AppendBcs(buf[:0], payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("BcsSerialize", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := payload.BcsSerialize(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
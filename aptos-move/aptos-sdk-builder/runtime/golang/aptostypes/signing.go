@@ -0,0 +1,372 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Domain separation prefix of the Aptos crypto hashers.
+const hashPrefix = "APTOS::"
+
+// Seeds prepended to the BCS bytes of values before they are signed or hashed, i.e.
+// `SHA3-256("APTOS::" || typeName)` for the Rust type names. Go has no array constants, so
+// these are variables, which must not be modified.
+var (
+	// RawTransactionSeed starts the message signed by the sender of a `RawTransaction`.
+	RawTransactionSeed = [32]byte{
+		0xb5, 0xe9, 0x7d, 0xb0, 0x7f, 0xa0, 0xbd, 0x0e, 0x55, 0x98, 0xaa, 0x36, 0x43, 0xa9, 0xbc, 0x6f,
+		0x66, 0x93, 0xbd, 0xdc, 0x1a, 0x9f, 0xec, 0x9e, 0x67, 0x4a, 0x46, 0x1e, 0xaa, 0x00, 0xb1, 0x93,
+	}
+	// RawTransactionWithDataSeed starts the message signed by the signers of a multi-agent
+	// transaction.
+	RawTransactionWithDataSeed = [32]byte{
+		0x5e, 0xfa, 0x3c, 0x4f, 0x02, 0xf8, 0x3a, 0x0f, 0x4b, 0x2d, 0x69, 0xfc, 0x95, 0xc6, 0x07, 0xcc,
+		0x02, 0x82, 0x5c, 0xc4, 0xe7, 0xbe, 0x53, 0x6e, 0xf0, 0x99, 0x2d, 0xf0, 0x50, 0xd9, 0xe6, 0x7c,
+	}
+	// TransactionSeed starts the data hashed to identify a `Transaction`.
+	TransactionSeed = [32]byte{
+		0xfa, 0x21, 0x0a, 0x94, 0x17, 0xef, 0x3e, 0x7f, 0xa4, 0x5b, 0xfa, 0x1d, 0x17, 0xa8, 0xdb, 0xd4,
+		0xd8, 0x83, 0x71, 0x19, 0x10, 0xa5, 0x50, 0xd2, 0x65, 0xfe, 0xe1, 0x89, 0xe9, 0x26, 0x6d, 0xd4,
+	}
+)
+
+// Scheme bytes appended to a public key to derive its authentication key, as defined by
+// `Scheme` in Rust.
+const (
+	ed25519Scheme      byte = 0
+	multiEd25519Scheme byte = 1
+)
+
+// AuthKeyFromEd25519 returns the authentication key of a single-key account, i.e.
+// `SHA3-256(publicKey || 0x00)`. It is also the address of the account created with this key.
+func AuthKeyFromEd25519(publicKey ed25519.PublicKey) AccountAddress {
+	return authKey(publicKey, ed25519Scheme)
+}
+
+func authKey(publicKey []byte, scheme byte) AccountAddress {
+	h := sha3.New256()
+	h.Write(publicKey)
+	h.Write([]byte{scheme})
+	var key AccountAddress
+	h.Sum(key[:0])
+	return key
+}
+
+// ResourceAccountAddress returns the address of the resource account created by `source`
+// with `seed`, as computed by `account::create_resource_account` in Move, i.e.
+// `SHA3-256(source || seed)`. The address is known before the account is created.
+func ResourceAccountAddress(source AccountAddress, seed []byte) AccountAddress {
+	h := sha3.New256()
+	h.Write(source[:])
+	h.Write(seed)
+	var address AccountAddress
+	h.Sum(address[:0])
+	return address
+}
+
+// HashOption configures the hash function of the signing and hashing helpers below.
+type HashOption func(*hashConfig)
+
+type hashConfig struct {
+	newHash func() hash.Hash
+	// Whether `newHash` was replaced, so that the precomputed seeds do not apply.
+	customHash bool
+}
+
+// WithHasher replaces SHA3-256, e.g. by an instrumented hasher in tests. Signatures and
+// hashes computed with any other function are not accepted by the nodes.
+func WithHasher(newHash func() hash.Hash) HashOption {
+	return func(config *hashConfig) {
+		config.newHash = newHash
+		config.customHash = true
+	}
+}
+
+func newHashConfig(options []HashOption) hashConfig {
+	config := hashConfig{newHash: sha3.New256}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func (config hashConfig) sum(data []byte) []byte {
+	h := config.newHash()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Return the seed prepended to the BCS bytes of a value of the given Rust type name before it
+// is signed, in a new buffer: `seed` itself, or the hash of `"APTOS::" || typeName` with a
+// custom hasher.
+func (config hashConfig) signingSeed(typeName string, seed *[32]byte) []byte {
+	if config.customHash {
+		return config.sum([]byte(hashPrefix + typeName))
+	}
+	return append([]byte(nil), seed[:]...)
+}
+
+// SigningMessage returns the message that the sender signs: the `RawTransaction` domain
+// separator followed by the BCS bytes of the transaction.
+func (obj *RawTransaction) SigningMessage(options ...HashOption) ([]byte, error) {
+	return AppendBcs(newHashConfig(options).signingSeed("RawTransaction", &RawTransactionSeed), obj)
+}
+
+// MultiAgentSigningMessage returns the message that the sender and every secondary signer of
+// a multi-agent transaction sign, i.e. the signing message of
+// `RawTransactionWithData::MultiAgent`.
+func (obj *RawTransaction) MultiAgentSigningMessage(
+	secondarySignerAddresses []AccountAddress,
+	options ...HashOption,
+) ([]byte, error) {
+	s := &Serializer{buf: newHashConfig(options).signingSeed("RawTransactionWithData", &RawTransactionWithDataSeed)}
+	// `MultiAgent` is variant 0 of `RawTransactionWithData`.
+	if err := s.SerializeVariantIndex(0); err != nil {
+		return nil, err
+	}
+	if err := obj.Serialize(s); err != nil {
+		return nil, err
+	}
+	if err := s.SerializeLen(uint64(len(secondarySignerAddresses))); err != nil {
+		return nil, err
+	}
+	for i := range secondarySignerAddresses {
+		if err := secondarySignerAddresses[i].Serialize(s); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetBytes(), nil
+}
+
+// SignEd25519 signs the transaction with an Ed25519 private key and wraps the signature in
+// an Ed25519 `TransactionAuthenticator`. The BCS bytes of the result can be submitted to
+// the REST API as is.
+func (obj *RawTransaction) SignEd25519(privKey ed25519.PrivateKey, options ...HashOption) (*SignedTransaction, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key length")
+	}
+	message, err := obj.SigningMessage(options...)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTransaction{
+		RawTxn: *obj,
+		Authenticator: &TransactionAuthenticator__Ed25519{
+			PublicKey: Ed25519PublicKey(privKey.Public().(ed25519.PublicKey)),
+			Signature: Ed25519Signature(ed25519.Sign(privKey, message)),
+		},
+	}, nil
+}
+
+// WithEd25519Authenticator wraps a signature of `SigningMessage` produced elsewhere, e.g. by
+// a hardware wallet, in an Ed25519 `TransactionAuthenticator`. The signature is verified
+// so that a wrong key or message is reported before the transaction is submitted.
+func (obj *RawTransaction) WithEd25519Authenticator(
+	pubKey ed25519.PublicKey,
+	signature []byte,
+	options ...HashOption,
+) (*SignedTransaction, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key length")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return nil, errors.New("invalid ed25519 signature length")
+	}
+	message, err := obj.SigningMessage(options...)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pubKey, message, signature) {
+		return nil, errors.New("ed25519 signature does not match the signing message")
+	}
+	return &SignedTransaction{
+		RawTxn: *obj,
+		Authenticator: &TransactionAuthenticator__Ed25519{
+			PublicKey: Ed25519PublicKey(append([]byte(nil), pubKey...)),
+			Signature: Ed25519Signature(append([]byte(nil), signature...)),
+		},
+	}, nil
+}
+
+// SignEd25519AccountAuthenticator signs `message` for one of the signers of a multi-agent
+// transaction.
+func SignEd25519AccountAuthenticator(privKey ed25519.PrivateKey, message []byte) (*AccountAuthenticator__Ed25519, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key length")
+	}
+	return &AccountAuthenticator__Ed25519{
+		PublicKey: Ed25519PublicKey(privKey.Public().(ed25519.PublicKey)),
+		Signature: Ed25519Signature(ed25519.Sign(privKey, message)),
+	}, nil
+}
+
+// NewMultiAgentTransaction wraps the authenticators of the sender and of the secondary
+// signers of `rawTxn`. The secondary signers must be given in the same order as their
+// addresses, which is also the order used to compute `MultiAgentSigningMessage`.
+func NewMultiAgentTransaction(
+	rawTxn RawTransaction,
+	sender AccountAuthenticator,
+	secondarySignerAddresses []AccountAddress,
+	secondarySigners []AccountAuthenticator,
+) (*SignedTransaction, error) {
+	if len(secondarySignerAddresses) != len(secondarySigners) {
+		return nil, fmt.Errorf(
+			"got %d secondary signer addresses but %d secondary signers",
+			len(secondarySignerAddresses),
+			len(secondarySigners),
+		)
+	}
+	return &SignedTransaction{
+		RawTxn: rawTxn,
+		Authenticator: &TransactionAuthenticator__MultiAgent{
+			Sender:                   sender,
+			SecondarySignerAddresses: secondarySignerAddresses,
+			SecondarySigners:         secondarySigners,
+		},
+	}, nil
+}
+
+// Hash returns the hash identifying the transaction once it is submitted, e.g. in the REST
+// API: the SHA3-256 digest of the `Transaction` domain separator followed by the BCS bytes of
+// `Transaction::UserTransaction`.
+func (obj *SignedTransaction) Hash(options ...HashOption) ([]byte, error) {
+	config := newHashConfig(options)
+	data, err := AppendBcs(config.signingSeed("Transaction", &TransactionSeed), &Transaction__UserTransaction{Value: *obj})
+	if err != nil {
+		return nil, err
+	}
+	return config.sum(data), nil
+}
+
+// AuthenticatorKind returns the signing scheme of the transaction, named as the `type` of its
+// `signature` in the REST API: "ed25519_signature", "multi_ed25519_signature" or
+// "multi_agent_signature". It returns "" if the authenticator is missing.
+func (obj *SignedTransaction) AuthenticatorKind() string {
+	switch authenticator := obj.Authenticator.(type) {
+	case *TransactionAuthenticator__Ed25519:
+		if authenticator != nil {
+			return "ed25519_signature"
+		}
+	case *TransactionAuthenticator__MultiEd25519:
+		if authenticator != nil {
+			return "multi_ed25519_signature"
+		}
+	case *TransactionAuthenticator__MultiAgent:
+		if authenticator != nil {
+			return "multi_agent_signature"
+		}
+	}
+	return ""
+}
+
+// VerifyEd25519 checks the signatures of the transaction, e.g. to catch signing bugs before
+// it is submitted: the Ed25519 or MultiEd25519 signature of the sender against
+// `SigningMessage`, or the signatures of the sender and of every secondary signer of a
+// multi-agent transaction against `MultiAgentSigningMessage`. It returns false if a signature
+// does not match, and an error if an authenticator is missing or malformed.
+func (obj *SignedTransaction) VerifyEd25519(options ...HashOption) (bool, error) {
+	switch authenticator := obj.Authenticator.(type) {
+	case *TransactionAuthenticator__Ed25519:
+		if authenticator == nil {
+			break
+		}
+		message, err := obj.RawTxn.SigningMessage(options...)
+		if err != nil {
+			return false, err
+		}
+		return verifyEd25519(authenticator.PublicKey, authenticator.Signature, message)
+	case *TransactionAuthenticator__MultiEd25519:
+		if authenticator == nil {
+			break
+		}
+		message, err := obj.RawTxn.SigningMessage(options...)
+		if err != nil {
+			return false, err
+		}
+		return verifyMultiEd25519(authenticator.PublicKey, authenticator.Signature, message)
+	case *TransactionAuthenticator__MultiAgent:
+		if authenticator == nil {
+			break
+		}
+		if len(authenticator.SecondarySignerAddresses) != len(authenticator.SecondarySigners) {
+			return false, fmt.Errorf(
+				"got %d secondary signer addresses but %d secondary signers",
+				len(authenticator.SecondarySignerAddresses),
+				len(authenticator.SecondarySigners),
+			)
+		}
+		message, err := obj.RawTxn.MultiAgentSigningMessage(authenticator.SecondarySignerAddresses, options...)
+		if err != nil {
+			return false, err
+		}
+		if ok, err := verifyAccountAuthenticator(authenticator.Sender, message); !ok || err != nil {
+			return false, err
+		}
+		for i, signer := range authenticator.SecondarySigners {
+			ok, err := verifyAccountAuthenticator(signer, message)
+			if err != nil {
+				return false, fmt.Errorf("secondary signer %d: %w", i, err)
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return false, errors.New("missing transaction authenticator")
+}
+
+func verifyAccountAuthenticator(authenticator AccountAuthenticator, message []byte) (bool, error) {
+	switch authenticator := authenticator.(type) {
+	case *AccountAuthenticator__Ed25519:
+		if authenticator != nil {
+			return verifyEd25519(authenticator.PublicKey, authenticator.Signature, message)
+		}
+	case *AccountAuthenticator__MultiEd25519:
+		if authenticator != nil {
+			return verifyMultiEd25519(authenticator.PublicKey, authenticator.Signature, message)
+		}
+	}
+	return false, errors.New("missing account authenticator")
+}
+
+func verifyEd25519(publicKey Ed25519PublicKey, signature Ed25519Signature, message []byte) (bool, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false, errors.New("invalid ed25519 public key length")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return false, errors.New("invalid ed25519 signature length")
+	}
+	return ed25519.Verify(ed25519.PublicKey(publicKey), message, signature), nil
+}
+
+// The comparisons below take a time that only depends on the lengths of the values, not on
+// their contents, so that they do not leak how many leading bytes match.
+
+// ConstantTimeEqual reports whether the two keys are equal, in constant time.
+func (obj Ed25519PublicKey) ConstantTimeEqual(other Ed25519PublicKey) bool {
+	return subtle.ConstantTimeCompare(obj, other) == 1
+}
+
+// ConstantTimeEqual reports whether the two signatures are equal, in constant time.
+func (obj Ed25519Signature) ConstantTimeEqual(other Ed25519Signature) bool {
+	return subtle.ConstantTimeCompare(obj, other) == 1
+}
+
+// ConstantTimeEqual reports whether the two keys are equal, in constant time.
+func (obj MultiEd25519PublicKey) ConstantTimeEqual(other MultiEd25519PublicKey) bool {
+	return subtle.ConstantTimeCompare(obj, other) == 1
+}
+
+// ConstantTimeEqual reports whether the two signatures are equal, in constant time.
+func (obj MultiEd25519Signature) ConstantTimeEqual(other MultiEd25519Signature) bool {
+	return subtle.ConstantTimeCompare(obj, other) == 1
+}
@@ -0,0 +1,263 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func TestSigningSeedsMatchTheirTypeNames(t *testing.T) {
+	for typeName, seed := range map[string]*[32]byte{
+		"RawTransaction":         &RawTransactionSeed,
+		"RawTransactionWithData": &RawTransactionWithDataSeed,
+		"Transaction":            &TransactionSeed,
+	} {
+		if expected := sha3.Sum256([]byte(hashPrefix + typeName)); *seed != expected {
+			t.Errorf("wrong seed for %s: %x, expected %x", typeName, *seed, expected)
+		}
+	}
+}
+
+func TestVerifyEd25519(t *testing.T) {
+	privKey, _ := testKeyPair([32]byte{1})
+	raw := RawTransaction{SequenceNumber: 1, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	signed, err := raw.SignEd25519(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := signed.VerifyEd25519(); !ok || err != nil {
+		t.Fatalf("failed to verify a signed transaction: %v", err)
+	}
+
+	tampered := *signed
+	tampered.RawTxn.SequenceNumber++
+	if ok, err := tampered.VerifyEd25519(); ok || err != nil {
+		t.Fatalf("verified a tampered transaction: %v", err)
+	}
+	authenticator := *signed.Authenticator.(*TransactionAuthenticator__Ed25519)
+	authenticator.Signature = append(Ed25519Signature(nil), authenticator.Signature...)
+	authenticator.Signature[0] ^= 1
+	tampered = *signed
+	tampered.Authenticator = &authenticator
+	if ok, err := tampered.VerifyEd25519(); ok || err != nil {
+		t.Fatalf("verified a tampered signature: %v", err)
+	}
+	tampered.Authenticator = nil
+	if _, err := tampered.VerifyEd25519(); err == nil {
+		t.Fatal("verified a transaction without authenticator")
+	}
+
+	// A multi-agent transaction, whose secondary signer is checked too.
+	secondaryKey, _ := testKeyPair([32]byte{2})
+	secondaries := []AccountAddress{{31: 2}}
+	message, err := raw.MultiAgentSigningMessage(secondaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := SignEd25519AccountAuthenticator(privKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondary, err := SignEd25519AccountAuthenticator(secondaryKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multiAgent, err := NewMultiAgentTransaction(raw, sender, secondaries, []AccountAuthenticator{secondary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := multiAgent.VerifyEd25519(); !ok || err != nil {
+		t.Fatalf("failed to verify a multi-agent transaction: %v", err)
+	}
+	multiAgent.Authenticator.(*TransactionAuthenticator__MultiAgent).SecondarySignerAddresses[0][31] = 3
+	if ok, err := multiAgent.VerifyEd25519(); ok || err != nil {
+		t.Fatalf("verified a multi-agent transaction with a tampered signer address: %v", err)
+	}
+}
+
+func TestResourceAccountAddress(t *testing.T) {
+	// Known answers computed independently with Python's `hashlib.sha3_256`.
+	for _, test := range []struct {
+		source AccountAddress
+		seed   string
+		want   string
+	}{
+		{CoreCodeAddress, "", "0xb79151ec5d30a80b78789805f293fa4fb8fd1eebc0c9367e7c9106678a893df1"},
+		{AccountAddress{30: 0xca, 31: 0xfe}, "pool", "0xb85719c10aa93541a047cfc1239b2790bfb6b51c089788f590eb5d671760caa7"},
+	} {
+		if got := ResourceAccountAddress(test.source, []byte(test.seed)).ToHex(); got != test.want {
+			t.Errorf("%s with seed %q: expected %s, got %s", test.source.ToHexLiteral(), test.seed, test.want, got)
+		}
+	}
+}
+
+func TestAuthenticatorKind(t *testing.T) {
+	privKey, _ := testKeyPair([32]byte{1})
+	raw := RawTransaction{ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	signed, err := raw.SignEd25519(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// After a round trip through BCS, as for a transaction read from the node.
+	input, err := signed.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := BcsDeserializeSignedTransaction(input)
+	if err != nil || decoded.AuthenticatorKind() != "ed25519_signature" {
+		t.Fatalf("unexpected kind %q: %v", decoded.AuthenticatorKind(), err)
+	}
+
+	for _, test := range []struct {
+		authenticator TransactionAuthenticator
+		want          string
+	}{
+		{&TransactionAuthenticator__MultiEd25519{}, "multi_ed25519_signature"},
+		{&TransactionAuthenticator__MultiAgent{}, "multi_agent_signature"},
+		{nil, ""},
+		{(*TransactionAuthenticator__Ed25519)(nil), ""},
+	} {
+		signed := SignedTransaction{RawTxn: raw, Authenticator: test.authenticator}
+		if kind := signed.AuthenticatorKind(); kind != test.want {
+			t.Errorf("%T: expected %q, got %q", test.authenticator, test.want, kind)
+		}
+	}
+}
+
+func TestWithEd25519Authenticator(t *testing.T) {
+	privKey, pubKey := testKeyPair([32]byte{1})
+	raw := RawTransaction{SequenceNumber: 1, ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	message, err := raw.SigningMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As if signed by an external signer.
+	signature := ed25519.Sign(privKey, message)
+	external, err := raw.WithEd25519Authenticator(pubKey, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := raw.SignEd25519(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	externalBytes, err := external.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if localBytes, err := local.BcsSerialize(); err != nil || !bytes.Equal(externalBytes, localBytes) {
+		t.Fatalf("the external signature gave a different transaction: %v", err)
+	}
+
+	_, otherPubKey := testKeyPair([32]byte{2})
+	if _, err := raw.WithEd25519Authenticator(otherPubKey, signature); err == nil {
+		t.Fatal("accepted a signature by another key")
+	}
+	// The authenticator holds copies.
+	signature[1] ^= 1
+	if ok, err := external.VerifyEd25519(); !ok || err != nil {
+		t.Fatalf("the authenticator aliases the signature: %v", err)
+	}
+	if _, err := raw.WithEd25519Authenticator(pubKey, signature); err == nil {
+		t.Fatal("accepted a tampered signature")
+	}
+	if _, err := raw.WithEd25519Authenticator(pubKey[1:], signature); err == nil {
+		t.Fatal("accepted a short public key")
+	}
+	if _, err := raw.WithEd25519Authenticator(pubKey, signature[1:]); err == nil {
+		t.Fatal("accepted a short signature")
+	}
+}
+
+// A SHA3-256 hasher counting the bytes written to it.
+type countingHash struct {
+	hash.Hash
+	written *int
+}
+
+func (h countingHash) Write(p []byte) (int, error) {
+	*h.written += len(p)
+	return h.Hash.Write(p)
+}
+
+func TestWithHasher(t *testing.T) {
+	raw := RawTransaction{ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	message, err := raw.SigningMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawBytes, err := raw.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(message, append(RawTransactionSeed[:], rawBytes...)) {
+		t.Fatalf("unexpected signing message %x", message)
+	}
+
+	// SHA3-256 gives the same message, computing the seed instead of using the precomputed one.
+	written := 0
+	counted, err := raw.SigningMessage(WithHasher(func() hash.Hash { return countingHash{sha3.New256(), &written} }))
+	if err != nil || !bytes.Equal(counted, message) || written != len(hashPrefix+"RawTransaction") {
+		t.Fatalf("unexpected message with %d bytes hashed: %v", written, err)
+	}
+	if other, err := raw.SigningMessage(WithHasher(sha256.New)); err != nil || bytes.Equal(other, message) {
+		t.Fatalf("SHA-256 gave the SHA3-256 message: %v", err)
+	}
+
+	signed := SignedTransaction{RawTxn: raw, Authenticator: &TransactionAuthenticator__Ed25519{}}
+	want, err := signed.Hash()
+	if err != nil || len(want) != sha3.New256().Size() {
+		t.Fatalf("unexpected hash %x: %v", want, err)
+	}
+	if got, err := signed.Hash(WithHasher(sha3.New256)); err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("expected %x, got %x: %v", want, got, err)
+	}
+	if got, err := signed.Hash(WithHasher(sha256.New)); err != nil || bytes.Equal(got, want) {
+		t.Fatalf("SHA-256 gave the SHA3-256 hash: %v", err)
+	}
+}
+
+func TestMultiAgentSigningMessage(t *testing.T) {
+	raw := RawTransaction{ChainId: ChainIdTesting, Payload: &TransactionPayload__ScriptFunction{}}
+	secondaries := []AccountAddress{{31: 2}, {31: 3}}
+	message, err := raw.MultiAgentSigningMessage(secondaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawBytes, err := raw.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The seed, variant 0 of `RawTransactionWithData`, the transaction, then the addresses.
+	want := append(append(RawTransactionWithDataSeed[:], 0), rawBytes...)
+	want = append(append(append(want, 2), secondaries[0][:]...), secondaries[1][:]...)
+	if !bytes.Equal(message, want) {
+		t.Fatalf("unexpected signing message %x", message)
+	}
+
+	privKey, _ := testKeyPair([32]byte{1})
+	authenticator, err := SignEd25519AccountAuthenticator(privKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := NewMultiAgentTransaction(raw, authenticator, secondaries, []AccountAuthenticator{authenticator, authenticator})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signed.BcsSerialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMultiAgentTransaction(raw, authenticator, secondaries, []AccountAuthenticator{authenticator}); err == nil {
+		t.Fatal("accepted fewer secondary signers than addresses")
+	}
+	if _, err := SignEd25519AccountAuthenticator(privKey[:32], message); err == nil {
+		t.Fatal("accepted a short private key")
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+// TypeTagError is returned by `ParseTypeTag`, `ParseStructTag` and `ParseFunctionId` for
+// malformed input.
+type TypeTagError struct {
+	Input string
+	// Position of the problem in the input, in runes.
+	Offset int
+	// What was wrong or expected, e.g. "expected `>` or `,`".
+	Msg string
+	// What was parsed, "type tag" if empty.
+	kind string
+}
+
+func (e *TypeTagError) Error() string {
+	kind := e.kind
+	if kind == "" {
+		kind = "type tag"
+	}
+	return fmt.Sprintf("invalid %s %q: %s at position %d", kind, e.Input, e.Msg, e.Offset)
+}
+
+// The primitive Move types. Their variants have no fields, so they can be shared.
+var (
+	BoolTypeTag    TypeTag = &TypeTag__Bool{}
+	U8TypeTag      TypeTag = &TypeTag__U8{}
+	U64TypeTag     TypeTag = &TypeTag__U64{}
+	U128TypeTag    TypeTag = &TypeTag__U128{}
+	AddressTypeTag TypeTag = &TypeTag__Address{}
+	SignerTypeTag  TypeTag = &TypeTag__Signer{}
+)
+
+// VectorTypeTag returns the type `vector<inner>`.
+func VectorTypeTag(inner TypeTag) TypeTag {
+	return &TypeTag__Vector{Value: inner}
+}
+
+// StructTypeTag returns the struct type `address::module::name<typeArgs...>`, e.g.
+// `StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin")` for
+// "0x1::aptos_coin::AptosCoin". The names are not validated, see `ValidateTypeTag`.
+func StructTypeTag(address AccountAddress, module, name Identifier, typeArgs ...TypeTag) TypeTag {
+	return &TypeTag__Struct{Value: StructTag{Address: address, Module: module, Name: name, TypeArgs: typeArgs}}
+}
+
+// ParseTypeTag parses a Move type written in its canonical form, e.g. "u64", "vector<u8>"
+// or "0x1::coin::Coin<0x1::aptos_coin::AptosCoin>". Addresses need their "0x" prefix, and
+// types nested too deeply for `BcsSerialize` are rejected. Syntax errors are `*TypeTagError`s.
+func ParseTypeTag(s string) (TypeTag, error) {
+	p := typeTagParser{input: s}
+	tag, err := p.parseTypeTag()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, p.errorAt(p.pos, "unexpected trailing characters")
+	}
+	return tag, nil
+}
+
+// ParseStructTag parses a fully-qualified Move struct type such as "0x1::aptos_coin::AptosCoin".
+func ParseStructTag(s string) (StructTag, error) {
+	tag, err := ParseTypeTag(s)
+	if err != nil {
+		return StructTag{}, err
+	}
+	if tag, ok := tag.(*TypeTag__Struct); ok {
+		return tag.Value, nil
+	}
+	return StructTag{}, fmt.Errorf("invalid struct tag %q: not a struct type", s)
+}
+
+// ParseFunctionId parses the ID of a Move function such as "0x1::coin::transfer" into its
+// module and name.
+func ParseFunctionId(s string) (ModuleId, Identifier, error) {
+	p := typeTagParser{input: s, kind: "function id"}
+	p.skipSpaces()
+	start := p.pos
+	word := p.word()
+	if word == "" {
+		return ModuleId{}, "", p.errorAt(start, "expected an address")
+	}
+	address, err := ParseAccountAddress(word)
+	if err != nil {
+		return ModuleId{}, "", p.errorAt(start, "invalid address `%s`", word)
+	}
+	module := ModuleId{Address: address}
+	if err := p.expect("::"); err != nil {
+		return ModuleId{}, "", err
+	}
+	if module.Name, err = p.identifier(); err != nil {
+		return ModuleId{}, "", err
+	}
+	if err := p.expect("::"); err != nil {
+		return ModuleId{}, "", err
+	}
+	function, err := p.identifier()
+	if err != nil {
+		return ModuleId{}, "", err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return ModuleId{}, "", p.errorAt(p.pos, "unexpected trailing characters")
+	}
+	return module, function, nil
+}
+
+// NewScriptFunction builds a script function call from its ID, e.g.
+// "0x1::coin::transfer", its type arguments in canonical form, e.g.
+// "0x1::aptos_coin::AptosCoin", and its BCS-encoded arguments.
+// Syntax errors are `*TypeTagError`s.
+func NewScriptFunction(functionId string, typeArgs []string, args [][]byte) (*ScriptFunction, error) {
+	module, function, err := ParseFunctionId(functionId)
+	if err != nil {
+		return nil, err
+	}
+	tyArgs := make([]TypeTag, len(typeArgs))
+	for i, typeArg := range typeArgs {
+		if tyArgs[i], err = ParseTypeTag(typeArg); err != nil {
+			return nil, fmt.Errorf("type argument %d: %w", i, err)
+		}
+	}
+	return &ScriptFunction{Module: module, Function: function, TyArgs: tyArgs, Args: args}, nil
+}
+
+// String returns the canonical form of the struct type, with addresses in their
+// `ToHexLiteral` form as printed by the node, e.g.
+// "0x1::coin::Coin<0x1::aptos_coin::AptosCoin>".
+func (obj StructTag) String() string {
+	var b strings.Builder
+	b.WriteString(obj.Address.ToHexLiteral())
+	b.WriteString("::")
+	b.WriteString(string(obj.Module))
+	b.WriteString("::")
+	b.WriteString(string(obj.Name))
+	if len(obj.TypeArgs) > 0 {
+		b.WriteString("<")
+		for i, arg := range obj.TypeArgs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(typeTagString(arg))
+		}
+		b.WriteString(">")
+	}
+	return b.String()
+}
+
+// String returns the canonical form of the module ID, e.g. "0x1::coin", as in
+// `StructTag.String`.
+func (obj ModuleId) String() string {
+	return obj.Address.ToHexLiteral() + "::" + string(obj.Name)
+}
+
+func (*TypeTag__Bool) String() string    { return "bool" }
+func (*TypeTag__U8) String() string      { return "u8" }
+func (*TypeTag__U64) String() string     { return "u64" }
+func (*TypeTag__U128) String() string    { return "u128" }
+func (*TypeTag__Address) String() string { return "address" }
+func (*TypeTag__Signer) String() string  { return "signer" }
+
+func (obj *TypeTag__Vector) String() string {
+	return "vector<" + typeTagString(obj.Value) + ">"
+}
+
+func (obj *TypeTag__Struct) String() string {
+	return obj.Value.String()
+}
+
+// The generated `TypeTag` interface does not include `fmt.Stringer`.
+func typeTagString(tag TypeTag) string {
+	if tag, ok := tag.(fmt.Stringer); ok {
+		return tag.String()
+	}
+	return fmt.Sprintf("%v", tag)
+}
+
+// CloneTypeTag returns a deep copy of `tag`, sharing no memory with it. Variants without
+// fields are returned as is.
+func CloneTypeTag(tag TypeTag) TypeTag {
+	switch tag := tag.(type) {
+	case *TypeTag__Vector:
+		if tag != nil {
+			return &TypeTag__Vector{Value: CloneTypeTag(tag.Value)}
+		}
+	case *TypeTag__Struct:
+		if tag != nil {
+			return &TypeTag__Struct{Value: tag.Value.Clone()}
+		}
+	}
+	return tag
+}
+
+// Clone returns a deep copy of the struct type, including its type arguments.
+func (obj StructTag) Clone() StructTag {
+	clone := obj
+	if obj.TypeArgs != nil {
+		clone.TypeArgs = make([]TypeTag, len(obj.TypeArgs))
+		for i, arg := range obj.TypeArgs {
+			clone.TypeArgs[i] = CloneTypeTag(arg)
+		}
+	}
+	return clone
+}
+
+// EqualTypeTag reports whether the two type tags denote the same type. Unlike
+// `reflect.DeepEqual`, it treats nil and empty type argument lists as equal.
+func EqualTypeTag(a, b TypeTag) bool {
+	switch a := a.(type) {
+	case *TypeTag__Vector:
+		b, ok := b.(*TypeTag__Vector)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		return EqualTypeTag(a.Value, b.Value)
+	case *TypeTag__Struct:
+		b, ok := b.(*TypeTag__Struct)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		return a.Value.Equal(b.Value)
+	case *TypeTag__Bool:
+		_, ok := b.(*TypeTag__Bool)
+		return ok
+	case *TypeTag__U8:
+		_, ok := b.(*TypeTag__U8)
+		return ok
+	case *TypeTag__U64:
+		_, ok := b.(*TypeTag__U64)
+		return ok
+	case *TypeTag__U128:
+		_, ok := b.(*TypeTag__U128)
+		return ok
+	case *TypeTag__Address:
+		_, ok := b.(*TypeTag__Address)
+		return ok
+	case *TypeTag__Signer:
+		_, ok := b.(*TypeTag__Signer)
+		return ok
+	}
+	return a == nil && b == nil
+}
+
+// Equal reports whether the two struct types are the same, including their type arguments.
+func (obj StructTag) Equal(other StructTag) bool {
+	return obj.Address == other.Address &&
+		obj.Module == other.Module &&
+		obj.Name == other.Name &&
+		equalTypeTags(obj.TypeArgs, other.TypeArgs)
+}
+
+// Equal reports whether the two module IDs are the same. It is equivalent to `==`.
+func (obj ModuleId) Equal(other ModuleId) bool {
+	return obj == other
+}
+
+func equalTypeTags(a, b []TypeTag) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !EqualTypeTag(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+type typeTagParser struct {
+	input string
+	pos   int
+	// Reported in errors, see `TypeTagError`.
+	kind string
+	// Containers entered so far, counted as `BcsSerialize` does.
+	depth uint64
+}
+
+// Report a problem at the byte offset `pos`.
+func (p *typeTagParser) errorAt(pos int, format string, args ...interface{}) error {
+	return &TypeTagError{
+		Input:  p.input,
+		Offset: utf8.RuneCountInString(p.input[:pos]),
+		Msg:    fmt.Sprintf(format, args...),
+		kind:   p.kind,
+	}
+}
+
+// Enter a container at `pos`, failing on types which could not be serialized.
+func (p *typeTagParser) enter(pos int) error {
+	if p.depth >= bcs.MaxContainerDepth {
+		return p.errorAt(pos, "type nested deeper than %d containers", bcs.MaxContainerDepth)
+	}
+	p.depth++
+	return nil
+}
+
+func (p *typeTagParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// Consume `token` (after optional spaces) or fail.
+func (p *typeTagParser) expect(token string) error {
+	p.skipSpaces()
+	if !strings.HasPrefix(p.input[p.pos:], token) {
+		return p.errorAt(p.pos, "expected `%s`", token)
+	}
+	p.pos += len(token)
+	return nil
+}
+
+// Read the longest sequence of alphanumeric characters and underscores.
+func (p *typeTagParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeTagParser) identifier() (Identifier, error) {
+	start := p.pos
+	name := p.word()
+	if name == "" {
+		return "", p.errorAt(p.pos, "expected an identifier")
+	}
+	if !isValidIdentifier(name) {
+		return "", p.errorAt(start, "invalid identifier `%s`", name)
+	}
+	return Identifier(name), nil
+}
+
+func (p *typeTagParser) parseTypeTag() (TypeTag, error) {
+	p.skipSpaces()
+	start := p.pos
+	defer func(depth uint64) { p.depth = depth }(p.depth)
+	if err := p.enter(start); err != nil {
+		return nil, err
+	}
+	word := p.word()
+	switch word {
+	case "bool":
+		return &TypeTag__Bool{}, nil
+	case "u8":
+		return &TypeTag__U8{}, nil
+	case "u64":
+		return &TypeTag__U64{}, nil
+	case "u128":
+		return &TypeTag__U128{}, nil
+	case "address":
+		return &TypeTag__Address{}, nil
+	case "signer":
+		return &TypeTag__Signer{}, nil
+	case "vector":
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseTypeTag()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		return &TypeTag__Vector{Value: inner}, nil
+	case "":
+		return nil, p.errorAt(start, "expected a type")
+	}
+	if !strings.HasPrefix(word, "0x") {
+		return nil, p.errorAt(start, "unknown type `%s`", word)
+	}
+	address, err := ParseAccountAddress(word)
+	if err != nil {
+		return nil, p.errorAt(start, "invalid address `%s`", word)
+	}
+	// The struct tag is a container inside the `TypeTag` variant, and so are its address
+	// and identifiers, one level deeper.
+	if err := p.enter(start); err != nil {
+		return nil, err
+	}
+	if err := p.enter(start); err != nil {
+		return nil, err
+	}
+	p.depth--
+	tag := StructTag{Address: address}
+	if err := p.expect("::"); err != nil {
+		return nil, err
+	}
+	if tag.Module, err = p.identifier(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("::"); err != nil {
+		return nil, err
+	}
+	if tag.Name, err = p.identifier(); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !strings.HasPrefix(p.input[p.pos:], "<") {
+		return &TypeTag__Struct{Value: tag}, nil
+	}
+	p.pos++
+	for {
+		param, err := p.parseTypeTag()
+		if err != nil {
+			return nil, err
+		}
+		tag.TypeArgs = append(tag.TypeArgs, param)
+		p.skipSpaces()
+		if strings.HasPrefix(p.input[p.pos:], ",") {
+			p.pos++
+			continue
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, p.errorAt(p.pos, "expected `>` or `,`")
+		}
+		return &TypeTag__Struct{Value: tag}, nil
+	}
+}
+
+// Move identifiers start with a letter or an underscore, followed by letters, digits
+// or underscores. A single underscore is not a valid identifier.
+func isValidIdentifier(s string) bool {
+	if s == "" || s == "_" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
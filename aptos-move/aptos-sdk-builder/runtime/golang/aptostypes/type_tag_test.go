@@ -0,0 +1,197 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+)
+
+func TestParseTypeTag(t *testing.T) {
+	tag, err := ParseTypeTag("0x1::coin::Coin<0x1::aptos_coin::AptosCoin>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &TypeTag__Struct{Value: StructTag{Address: CoreCodeAddress, Module: "coin", Name: "Coin", TypeArgs: []TypeTag{
+		&TypeTag__Struct{Value: StructTag{Address: CoreCodeAddress, Module: "aptos_coin", Name: "AptosCoin"}},
+	}}}
+	if !reflect.DeepEqual(tag, want) {
+		t.Fatalf("unexpected type tag %#v", tag)
+	}
+
+	for _, s := range []string{
+		"u8",
+		"vector<vector<u8>>",
+		"0x1::coin::Coin<0x1::aptos_coin::AptosCoin, u64>",
+	} {
+		if tag, err := ParseTypeTag(s); err != nil || tag.(fmt.Stringer).String() != s {
+			t.Errorf("%q: failed to parse and print: %v", s, err)
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"u16",
+		"u8>",
+		"vector<u8",
+		"vector<>",
+		"0x1::coin",
+		"0x1::0coin::Coin",
+		"0x1::coin::Coin<>",
+		"0x1::coin::Coin<u64",
+		"0x1::coin::Coin<u64,>",
+		"0xZZ::coin::Coin",
+		"add::m::S",
+		"1::coin::Coin",
+		"0x1::coin::Coin<1::aptos_coin::AptosCoin>",
+	} {
+		var e *TypeTagError
+		if _, err := ParseTypeTag(s); !errors.As(err, &e) {
+			t.Errorf("%q: expected a syntax error, got %v", s, err)
+		}
+	}
+}
+
+func TestParseTypeTagDepth(t *testing.T) {
+	nested := func(depth int, inner string) string {
+		return strings.Repeat("vector<", depth) + inner + strings.Repeat(">", depth)
+	}
+	// Every type is a container, and a struct type adds its tag, then its address.
+	for _, s := range []string{
+		nested(bcs.MaxContainerDepth-1, "u8"),
+		nested(bcs.MaxContainerDepth-3, "0x1::m::S<u8>"),
+	} {
+		tag, err := ParseTypeTag(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tag.BcsSerialize(); err != nil {
+			t.Fatalf("the deepest type tag does not serialize: %v", err)
+		}
+	}
+	for _, s := range []string{
+		nested(bcs.MaxContainerDepth, "u8"),
+		nested(bcs.MaxContainerDepth-2, "0x1::m::S"),
+		nested(bcs.MaxContainerDepth-3, "0x1::m::S<vector<u8>>"),
+		nested(1000, "u8"),
+	} {
+		var e *TypeTagError
+		if _, err := ParseTypeTag(s); !errors.As(err, &e) || !strings.Contains(e.Msg, "nested deeper") {
+			t.Errorf("expected the nesting to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestTypeTagConstructors(t *testing.T) {
+	coinStore := StructTypeTag(CoreCodeAddress, "coin", "CoinStore", StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin"), U64TypeTag)
+	tag := VectorTypeTag(coinStore)
+	parsed, err := ParseTypeTag("vector<0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin, u64>>")
+	if err != nil || !reflect.DeepEqual(tag, parsed) {
+		t.Fatalf("expected %v, got %v, %v", tag, parsed, err)
+	}
+	if coin := StructTypeTag(CoreCodeAddress, "aptos_coin", "AptosCoin"); coin.(*TypeTag__Struct).Value.TypeArgs != nil {
+		t.Fatal("a struct type without type arguments should have nil TypeArgs")
+	}
+	for _, test := range []struct {
+		tag  TypeTag
+		want string
+	}{
+		{BoolTypeTag, "bool"},
+		{U8TypeTag, "u8"},
+		{U64TypeTag, "u64"},
+		{U128TypeTag, "u128"},
+		{AddressTypeTag, "address"},
+		{SignerTypeTag, "signer"},
+	} {
+		if parsed, err := ParseTypeTag(test.want); err != nil || !reflect.DeepEqual(parsed, test.tag) {
+			t.Errorf("%s: expected %v, got %v, %v", test.want, test.tag, parsed, err)
+		}
+	}
+}
+
+func TestCloneTypeTag(t *testing.T) {
+	tag, err := ParseTypeTag("0x1::coin::Coin<vector<0x1::m::T>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := CloneTypeTag(tag)
+	if !reflect.DeepEqual(clone, tag) {
+		t.Fatalf("unexpected clone %v", clone)
+	}
+	// Changing the clone in place must leave the original as it was.
+	clone.(*TypeTag__Struct).Value.TypeArgs[0].(*TypeTag__Vector).Value.(*TypeTag__Struct).Value.Name = "U"
+	if fmt.Sprint(tag) != "0x1::coin::Coin<vector<0x1::m::T>>" {
+		t.Fatalf("the clone shares memory with %v", tag)
+	}
+	for _, tag := range []TypeTag{nil, U64TypeTag, (*TypeTag__Struct)(nil)} {
+		if clone := CloneTypeTag(tag); clone != tag {
+			t.Errorf("%#v: unexpected clone %#v", tag, clone)
+		}
+	}
+}
+
+func TestTypeTagErrorOffset(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		offset int
+	}{
+		{"0x1::coin::Coin<u64", 19},
+		{"0xZZ::coin::Coin", 0},
+		{"0x1::::Coin", 5},
+		{"vector<u8", 9},
+		{"u64 x", 4},
+		{"0x1::a::B<u8, é>", 14},
+		{"é::a::b", 0},
+		{"0x1::é::b", 5},
+	} {
+		_, err := ParseTypeTag(test.input)
+		var e *TypeTagError
+		if !errors.As(err, &e) || e.Input != test.input || e.Offset != test.offset {
+			t.Errorf("%q: expected an error at position %d, got %v", test.input, test.offset, err)
+		}
+	}
+
+	_, _, err := ParseFunctionId("0x1::coin")
+	var e *TypeTagError
+	if !errors.As(err, &e) || e.Offset != 9 || e.Error() != "invalid function id \"0x1::coin\": expected `::` at position 9" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The parser stops at the first non-ASCII character, so count the runes before a
+	// position past one directly.
+	p := typeTagParser{input: "0x1::é::b"}
+	if err := p.errorAt(len("0x1::é::"), "expected a type"); !errors.As(err, &e) || e.Offset != 8 {
+		t.Fatalf("expected the offset in runes, got %v", err)
+	}
+}
+
+func TestNewScriptFunction(t *testing.T) {
+	function, err := NewScriptFunction("0x1::coin::transfer", []string{"0x1::aptos_coin::AptosCoin"}, [][]byte{{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := coinTransferPayload().(*TransactionPayload__ScriptFunction).Value
+	want.Args = [][]byte{{1}}
+	if !reflect.DeepEqual(*function, want) {
+		t.Fatalf("unexpected call %#v", function)
+	}
+
+	for _, s := range []string{"", "0x1", "0x1::coin", "0x1::coin::", "0xzz::coin::f", "0x1::coin::f<u8>", "0x1::1coin::f"} {
+		var e *TypeTagError
+		if _, err := NewScriptFunction(s, nil, nil); !errors.As(err, &e) || e.Input != s {
+			t.Errorf("%q: expected a syntax error, got %v", s, err)
+		}
+	}
+	// The error of a type argument gives its index, and its offset within the type argument.
+	_, err = NewScriptFunction("0x1::coin::f", []string{"u8", "vector<"}, nil)
+	var e *TypeTagError
+	if !errors.As(err, &e) || e.Input != "vector<" || e.Offset != 7 || !strings.HasPrefix(err.Error(), "type argument 1: ") {
+		t.Fatalf("expected an error in type argument 1, got %v", err)
+	}
+}
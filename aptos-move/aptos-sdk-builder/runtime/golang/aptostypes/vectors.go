@@ -0,0 +1,298 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// Move vectors are plain Go slices in the generated script function builders. The helpers
+// below encode those which are not byte vectors.
+
+// SerializeBytesVector writes a Move `vector<vector<u8>>`, e.g. the bytecode of the modules
+// of a package: the number of vectors, then each vector with its own length prefix.
+func SerializeBytesVector(serializer serde.Serializer, value [][]byte) error {
+	if err := serializer.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	for _, item := range value {
+		if err := serializer.SerializeBytes(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeserializeBytesVector reads a Move `vector<vector<u8>>` written by `SerializeBytesVector`.
+func DeserializeBytesVector(deserializer serde.Deserializer) ([][]byte, error) {
+	length, err := deserializer.DeserializeLen()
+	if err != nil {
+		return nil, err
+	}
+	value := make([][]byte, length)
+	for i := range value {
+		if value[i], err = deserializer.DeserializeBytes(); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// BcsSerializeBytesVector returns the BCS encoding of a Move `vector<vector<u8>>`, as
+// expected in the arguments of a `ScriptFunction`. The output is allocated once at its final
+// size, since the modules of a package can weigh megabytes.
+func BcsSerializeBytesVector(value [][]byte) ([]byte, error) {
+	counter := new(lengthCounter)
+	if err := SerializeBytesVector(counter, value); err != nil {
+		return nil, err
+	}
+	serializer := NewSerializerWithCapacity(int(counter.length))
+	if err := SerializeBytesVector(serializer, value); err != nil {
+		return nil, err
+	}
+	return serializer.GetBytes(), nil
+}
+
+// BcsDeserializeBytesVector decodes a Move `vector<vector<u8>>` and checks that the input
+// is entirely consumed.
+func BcsDeserializeBytesVector(input []byte) ([][]byte, error) {
+	deserializer := NewDeserializer(input, DefaultDeserializerOptions)
+	value, err := DeserializeBytesVector(deserializer)
+	if err == nil && deserializer.GetBufferOffset() < uint64(len(input)) {
+		return nil, errors.New("some input bytes were not read")
+	}
+	return value, err
+}
+
+// CloneBytesVector returns a deep copy of `value`, sharing no memory with it.
+func CloneBytesVector(value [][]byte) [][]byte {
+	if value == nil {
+		return nil
+	}
+	clone := make([][]byte, len(value))
+	for i, item := range value {
+		clone[i] = append([]byte(nil), item...)
+	}
+	return clone
+}
+
+// EqualBytesVector reports whether the two vectors hold the same byte strings. Nil and
+// empty vectors, or byte strings, are equal.
+func EqualBytesVector(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatBytesVector formats `value` as a list of hex byte strings, e.g. `[0x0a0b, 0x]`.
+func FormatBytesVector(value [][]byte) string {
+	return formatVector(len(value), func(i int) string { return "0x" + hex.EncodeToString(value[i]) })
+}
+
+// BcsSerializeAddressVector returns the BCS encoding of a Move `vector<address>`.
+func BcsSerializeAddressVector(value []AccountAddress) ([]byte, error) {
+	return bcsSerializeVector(len(value), func(serializer serde.Serializer, i int) error {
+		return value[i].Serialize(serializer)
+	})
+}
+
+// BcsDeserializeAddressVector decodes a Move `vector<address>` and checks that the input is
+// entirely consumed.
+func BcsDeserializeAddressVector(input []byte) ([]AccountAddress, error) {
+	var value []AccountAddress
+	err := bcsDeserializeVector(input, func(deserializer serde.Deserializer) error {
+		item, err := DeserializeAccountAddress(deserializer)
+		value = append(value, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// EqualAddressVector reports whether the two vectors have the same elements. Nil and empty
+// vectors are equal.
+func EqualAddressVector(a, b []AccountAddress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatAddressVector formats `value` as a list of full-length hex addresses.
+func FormatAddressVector(value []AccountAddress) string {
+	return formatVector(len(value), func(i int) string { return value[i].ToHex() })
+}
+
+// BcsSerializeBoolVector returns the BCS encoding of a Move `vector<bool>`.
+func BcsSerializeBoolVector(value []bool) ([]byte, error) {
+	return bcsSerializeVector(len(value), func(serializer serde.Serializer, i int) error {
+		return serializer.SerializeBool(value[i])
+	})
+}
+
+// BcsDeserializeBoolVector decodes a Move `vector<bool>` and checks that the input is
+// entirely consumed.
+func BcsDeserializeBoolVector(input []byte) ([]bool, error) {
+	var value []bool
+	err := bcsDeserializeVector(input, func(deserializer serde.Deserializer) error {
+		item, err := deserializer.DeserializeBool()
+		value = append(value, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// EqualBoolVector reports whether the two vectors have the same elements. Nil and empty
+// vectors are equal.
+func EqualBoolVector(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BcsSerializeU64Vector returns the BCS encoding of a Move `vector<u64>`.
+func BcsSerializeU64Vector(value []uint64) ([]byte, error) {
+	return bcsSerializeVector(len(value), func(serializer serde.Serializer, i int) error {
+		return serializer.SerializeU64(value[i])
+	})
+}
+
+// BcsDeserializeU64Vector decodes a Move `vector<u64>` and checks that the input is
+// entirely consumed.
+func BcsDeserializeU64Vector(input []byte) ([]uint64, error) {
+	var value []uint64
+	err := bcsDeserializeVector(input, func(deserializer serde.Deserializer) error {
+		item, err := deserializer.DeserializeU64()
+		value = append(value, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// EqualU64Vector reports whether the two vectors have the same elements. Nil and empty
+// vectors are equal.
+func EqualU64Vector(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BcsSerializeU128Vector returns the BCS encoding of a Move `vector<u128>`.
+func BcsSerializeU128Vector(value []serde.Uint128) ([]byte, error) {
+	return bcsSerializeVector(len(value), func(serializer serde.Serializer, i int) error {
+		return serializer.SerializeU128(value[i])
+	})
+}
+
+// BcsDeserializeU128Vector decodes a Move `vector<u128>` and checks that the input is
+// entirely consumed.
+func BcsDeserializeU128Vector(input []byte) ([]serde.Uint128, error) {
+	var value []serde.Uint128
+	err := bcsDeserializeVector(input, func(deserializer serde.Deserializer) error {
+		item, err := deserializer.DeserializeU128()
+		value = append(value, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// EqualU128Vector reports whether the two vectors have the same elements. Nil and empty
+// vectors are equal.
+func EqualU128Vector(a, b []serde.Uint128) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatU128Vector formats `value` as a list of decimal integers.
+func FormatU128Vector(value []serde.Uint128) string {
+	return formatVector(len(value), func(i int) string { return U128(value[i]).String() })
+}
+
+// Encode a vector of `length` elements, the i-th of which is written by `serializeItem`.
+func bcsSerializeVector(length int, serializeItem func(serializer serde.Serializer, i int) error) ([]byte, error) {
+	serializer := bcs.NewSerializer()
+	if err := serializer.SerializeLen(uint64(length)); err != nil {
+		return nil, err
+	}
+	for i := 0; i < length; i++ {
+		if err := serializeItem(serializer, i); err != nil {
+			return nil, err
+		}
+	}
+	return serializer.GetBytes(), nil
+}
+
+// Decode a vector whose elements are read one after the other by `deserializeItem`.
+func bcsDeserializeVector(input []byte, deserializeItem func(deserializer serde.Deserializer) error) error {
+	deserializer := NewDeserializer(input, DefaultDeserializerOptions)
+	length, err := deserializer.DeserializeLen()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < length; i++ {
+		if err := deserializeItem(deserializer); err != nil {
+			return err
+		}
+	}
+	if deserializer.GetBufferOffset() < uint64(len(input)) {
+		return errors.New("some input bytes were not read")
+	}
+	return nil
+}
+
+// Format a vector of `length` elements as `[a, b]`, the i-th of which is `formatItem(i)`.
+func formatVector(length int, formatItem func(i int) string) string {
+	items := make([]string, length)
+	for i := range items {
+		items[i] = formatItem(i)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
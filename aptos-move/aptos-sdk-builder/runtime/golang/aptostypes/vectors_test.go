@@ -0,0 +1,170 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/bcs"
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// largePackage returns the modules of a synthetic 8 MiB package: 64 modules of 128 KiB.
+func largePackage() [][]byte {
+	modules := make([][]byte, 64)
+	for i := range modules {
+		modules[i] = bytes.Repeat([]byte{byte(i)}, 128<<10)
+	}
+	return modules
+}
+
+func TestBcsSerializeBytesVector(t *testing.T) {
+	modules := largePackage()
+	output, err := BcsSerializeBytesVector(modules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap(output) != len(output) {
+		t.Fatalf("the output of %d bytes has a capacity of %d", len(output), cap(output))
+	}
+	serializer := bcs.NewSerializer()
+	if err := SerializeBytesVector(serializer, modules); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, serializer.GetBytes()) {
+		t.Fatal("unexpected encoding")
+	}
+	if decoded, err := BcsDeserializeBytesVector(output); err != nil || len(decoded) != len(modules) {
+		t.Fatalf("failed to decode the package: %v", err)
+	}
+}
+
+func TestBcsDeserializeBytesVector(t *testing.T) {
+	// The number of modules, then each module with its length.
+	input := []byte{2, 3, 0xa1, 0x1c, 0xeb, 2, 0x0b, 1}
+	decoded, err := BcsDeserializeBytesVector(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || !bytes.Equal(decoded[0], []byte{0xa1, 0x1c, 0xeb}) || !bytes.Equal(decoded[1], []byte{0x0b, 1}) {
+		t.Fatalf("unexpected modules %x", decoded)
+	}
+	if _, err := BcsDeserializeBytesVector(append(input, 0)); err == nil {
+		t.Fatal("accepted a trailing byte")
+	}
+	if _, err := BcsDeserializeBytesVector(input[:len(input)-1]); err == nil {
+		t.Fatal("accepted a truncated module")
+	}
+}
+
+func TestCloneBytesVector(t *testing.T) {
+	modules := [][]byte{{1, 2}, {}, nil}
+	clone := CloneBytesVector(modules)
+	if !EqualBytesVector(clone, modules) {
+		t.Fatalf("unexpected clone %x", clone)
+	}
+	clone[0][0] = 3
+	if modules[0][0] != 1 {
+		t.Fatal("the clone shares memory with the original")
+	}
+	if CloneBytesVector(nil) != nil {
+		t.Fatal("a nil vector was cloned as a non-nil one")
+	}
+}
+
+func TestBcsSerializeVectors(t *testing.T) {
+	addresses := []AccountAddress{{31: 1}, {31: 2}}
+	input, err := BcsSerializeAddressVector(addresses)
+	if err != nil || !bytes.Equal(input, append(append([]byte{2}, addresses[0][:]...), addresses[1][:]...)) {
+		t.Fatalf("unexpected encoding %x: %v", input, err)
+	}
+	if decoded, err := BcsDeserializeAddressVector(input); err != nil || !EqualAddressVector(decoded, addresses) {
+		t.Fatalf("unexpected addresses %v: %v", decoded, err)
+	}
+	if _, err := BcsDeserializeAddressVector(input[:40]); err == nil {
+		t.Fatal("accepted a truncated address")
+	}
+
+	amounts := []uint64{5, 0x0102}
+	input, err = BcsSerializeU64Vector(amounts)
+	if err != nil || !bytes.Equal(input, []byte{2, 5, 0, 0, 0, 0, 0, 0, 0, 2, 1, 0, 0, 0, 0, 0, 0}) {
+		t.Fatalf("unexpected encoding %x: %v", input, err)
+	}
+	if decoded, err := BcsDeserializeU64Vector(input); err != nil || !EqualU64Vector(decoded, amounts) {
+		t.Fatalf("unexpected amounts %v: %v", decoded, err)
+	}
+	if _, err := BcsDeserializeU64Vector(append(input, 0)); err == nil {
+		t.Fatal("accepted a trailing byte")
+	}
+
+	flags := []bool{true, false}
+	input, err = BcsSerializeBoolVector(flags)
+	if err != nil || !bytes.Equal(input, []byte{2, 1, 0}) {
+		t.Fatalf("unexpected encoding %x: %v", input, err)
+	}
+	if decoded, err := BcsDeserializeBoolVector(input); err != nil || !EqualBoolVector(decoded, flags) {
+		t.Fatalf("unexpected flags %v: %v", decoded, err)
+	}
+	if _, err := BcsDeserializeBoolVector([]byte{1, 2}); err == nil {
+		t.Fatal("accepted a non-canonical bool")
+	}
+
+	big := []serde.Uint128{{High: 1, Low: 2}}
+	input, err = BcsSerializeU128Vector(big)
+	if err != nil || len(input) != 17 {
+		t.Fatalf("unexpected encoding %x: %v", input, err)
+	}
+	if decoded, err := BcsDeserializeU128Vector(input); err != nil || !EqualU128Vector(decoded, big) {
+		t.Fatalf("unexpected integers %v: %v", decoded, err)
+	}
+	if _, err := BcsDeserializeU128Vector(input[:16]); err == nil {
+		t.Fatal("accepted a truncated integer")
+	}
+
+	// The empty vector.
+	if decoded, err := BcsDeserializeU64Vector([]byte{0}); err != nil || !EqualU64Vector(decoded, []uint64{}) {
+		t.Fatalf("unexpected amounts %v: %v", decoded, err)
+	}
+}
+
+func TestFormatVectors(t *testing.T) {
+	for _, test := range []struct {
+		got, want string
+	}{
+		{FormatBytesVector([][]byte{{0x0a, 0x0b}, {}}), "[0x0a0b, 0x]"},
+		{FormatAddressVector([]AccountAddress{{31: 1}}), "[" + AccountAddress{31: 1}.ToHex() + "]"},
+		{FormatU128Vector([]serde.Uint128{{Low: 5}, {High: 1}}), "[5, 18446744073709551616]"},
+		{FormatU128Vector(nil), "[]"},
+	} {
+		if test.got != test.want {
+			t.Errorf("expected %s, got %s", test.want, test.got)
+		}
+	}
+}
+
+// Compare with the growing buffer of `bcs.NewSerializer`, which `BcsSerializeBytesVector`
+// used before it sized its output:
+//
+//	go test -run NONE -bench BcsSerializeBytesVector
+func BenchmarkBcsSerializeBytesVector(b *testing.B) {
+	modules := largePackage()
+	b.Run("presized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := BcsSerializeBytesVector(modules); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("growing", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			serializer := bcs.NewSerializer()
+			if err := SerializeBytesVector(serializer, modules); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"errors"
+	"io"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// Byte vectors of at least this size, e.g. module bytecode, are written to the destination
+// directly instead of being copied into the serializer buffer first.
+const writerDirectThreshold = 32 * 1024
+
+// BcsSerializeTo writes the BCS encoding of `value` to `w`, the same bytes as
+// `value.BcsSerialize()`. Large byte vectors are streamed to `w` as they are produced, so
+// a multi-megabyte payload is never copied into a single buffer.
+func BcsSerializeTo(w io.Writer, value Serializable) error {
+	s := &writerSerializer{w: w}
+	if err := value.Serialize(s); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (obj *RawTransaction) BcsSerializeTo(w io.Writer) error {
+	return BcsSerializeTo(w, obj)
+}
+
+func (obj *SignedTransaction) BcsSerializeTo(w io.Writer) error {
+	return BcsSerializeTo(w, obj)
+}
+
+func (obj *TransactionPayload__WriteSet) BcsSerializeTo(w io.Writer) error {
+	return BcsSerializeTo(w, obj)
+}
+
+func (obj *TransactionPayload__Script) BcsSerializeTo(w io.Writer) error {
+	return BcsSerializeTo(w, obj)
+}
+
+func (obj *TransactionPayload__ModuleBundle) BcsSerializeTo(w io.Writer) error {
+	return BcsSerializeTo(w, obj)
+}
+
+func (obj *TransactionPayload__ScriptFunction) BcsSerializeTo(w io.Writer) error {
+	return BcsSerializeTo(w, obj)
+}
+
+// writerSerializer is a `Serializer` that hands its buffer to `w` whenever a large byte
+// vector is serialized. `flushed` counts the bytes already written.
+type writerSerializer struct {
+	Serializer
+	w       io.Writer
+	flushed uint64
+	err     error
+}
+
+var _ serde.Serializer = (*writerSerializer)(nil)
+
+func (s *writerSerializer) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	if _, err := s.w.Write(s.buf); err != nil {
+		s.err = err
+		return err
+	}
+	s.flushed += uint64(len(s.buf))
+	s.buf = s.buf[:0]
+	return nil
+}
+
+func (s *writerSerializer) GetBufferOffset() uint64 {
+	return s.flushed + uint64(len(s.buf))
+}
+
+// GetBytes returns the bytes that have not been written to `w` yet.
+func (s *writerSerializer) GetBytes() []byte {
+	return s.buf
+}
+
+func (s *writerSerializer) SerializeBytes(value []byte) error {
+	if len(value) < writerDirectThreshold {
+		return s.Serializer.SerializeBytes(value)
+	}
+	if err := s.SerializeLen(uint64(len(value))); err != nil {
+		return err
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(value); err != nil {
+		s.err = err
+		return err
+	}
+	s.flushed += uint64(len(value))
+	return nil
+}
+
+// SortMapEntries sorts the entries in the buffer. Maps are only found in small values, so
+// an entry that was already written to `w` makes the whole serialization fail.
+func (s *writerSerializer) SortMapEntries(offsets []uint64) {
+	if len(offsets) == 0 || s.err != nil {
+		return
+	}
+	if offsets[0] < s.flushed {
+		s.err = errors.New("cannot sort map entries that were already written")
+		return
+	}
+	relative := make([]uint64, len(offsets))
+	for i, offset := range offsets {
+		relative[i] = offset - s.flushed
+	}
+	s.Serializer.SortMapEntries(relative)
+}
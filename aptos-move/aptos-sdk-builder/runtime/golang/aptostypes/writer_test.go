@@ -0,0 +1,82 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package aptostypes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang/serde"
+)
+
+// A writer recording the length of every write.
+type recordingWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// A value sorting map entries after a large byte vector, which has been written out already.
+type lateMapValue struct{}
+
+func (lateMapValue) Serialize(serializer serde.Serializer) error {
+	start := serializer.GetBufferOffset()
+	if err := serializer.SerializeBytes(make([]byte, writerDirectThreshold)); err != nil {
+		return err
+	}
+	serializer.SortMapEntries([]uint64{start})
+	return nil
+}
+
+func TestBcsSerializeTo(t *testing.T) {
+	large := bytes.Repeat([]byte{7}, writerDirectThreshold)
+	payload := &TransactionPayload__ModuleBundle{Value: ModuleBundle{Codes: []Module{
+		{Code: []byte{1, 2}},
+		{Code: large},
+		{Code: bytes.Repeat([]byte{8}, writerDirectThreshold-1)},
+	}}}
+	want, err := payload.BcsSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w recordingWriter
+	if err := payload.BcsSerializeTo(&w); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Bytes(), want) {
+		t.Fatal("the written bytes differ from BcsSerialize")
+	}
+	// The bytes before the large module, the module itself, then the rest, which includes
+	// the module below the threshold.
+	if len(w.writes) != 3 || w.writes[1] != len(large) {
+		t.Fatalf("unexpected writes %v", w.writes)
+	}
+
+	raw := RawTransaction{ChainId: ChainIdTesting, Payload: payload}
+	if want, err = raw.BcsSerialize(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := raw.BcsSerializeTo(&buf); err != nil || !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("the written transaction differs from BcsSerialize: %v", err)
+	}
+
+	if err := payload.BcsSerializeTo(failingWriter{}); err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected the error of the writer, got %v", err)
+	}
+	if err := BcsSerializeTo(&buf, lateMapValue{}); err == nil {
+		t.Fatal("sorted map entries that were already written")
+	}
+}
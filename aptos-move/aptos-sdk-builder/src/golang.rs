@@ -2,6 +2,7 @@
 // SPDX-License-Identifier: Apache-2.0
 
 use crate::common;
+use aptos_crypto::HashValue;
 use aptos_types::transaction::{
     ArgumentABI, ScriptABI, ScriptFunctionABI, TransactionScriptABI, TypeArgumentABI,
 };
@@ -18,11 +19,317 @@ use serde_generate::{
 use heck::CamelCase;
 use std::{
     collections::BTreeMap,
-    io::{Result, Write},
+    io::{ErrorKind, Result, Write},
     path::PathBuf,
+    process::{Command, Stdio},
+    sync::Once,
 };
 
+/// Default Go module path of the Serde runtime, as imported by the hand-written sources below.
+const DEFAULT_SERDE_MODULE_PATH: &str =
+    "github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang";
+
+/// Hand-written Go sources completing the `aptostypes` package generated by serde-generate.
+const APTOS_TYPES_RUNTIME: &[(&str, &str)] = &[
+    (
+        "address.go",
+        include_str!("../runtime/golang/aptostypes/address.go"),
+    ),
+    (
+        "address_test.go",
+        include_str!("../runtime/golang/aptostypes/address_test.go"),
+    ),
+    (
+        "amount.go",
+        include_str!("../runtime/golang/aptostypes/amount.go"),
+    ),
+    (
+        "amount_test.go",
+        include_str!("../runtime/golang/aptostypes/amount_test.go"),
+    ),
+    (
+        "args.go",
+        include_str!("../runtime/golang/aptostypes/args.go"),
+    ),
+    (
+        "args_test.go",
+        include_str!("../runtime/golang/aptostypes/args_test.go"),
+    ),
+    (
+        "binary.go",
+        include_str!("../runtime/golang/aptostypes/binary.go"),
+    ),
+    (
+        "binary_test.go",
+        include_str!("../runtime/golang/aptostypes/binary_test.go"),
+    ),
+    (
+        "chain_id.go",
+        include_str!("../runtime/golang/aptostypes/chain_id.go"),
+    ),
+    (
+        "chain_id_test.go",
+        include_str!("../runtime/golang/aptostypes/chain_id_test.go"),
+    ),
+    (
+        "context.go",
+        include_str!("../runtime/golang/aptostypes/context.go"),
+    ),
+    (
+        "context_test.go",
+        include_str!("../runtime/golang/aptostypes/context_test.go"),
+    ),
+    (
+        "deserializer.go",
+        include_str!("../runtime/golang/aptostypes/deserializer.go"),
+    ),
+    (
+        "deserializer_test.go",
+        include_str!("../runtime/golang/aptostypes/deserializer_test.go"),
+    ),
+    (
+        "equal.go",
+        include_str!("../runtime/golang/aptostypes/equal.go"),
+    ),
+    (
+        "equal_test.go",
+        include_str!("../runtime/golang/aptostypes/equal_test.go"),
+    ),
+    (
+        "fixed_bytes.go",
+        include_str!("../runtime/golang/aptostypes/fixed_bytes.go"),
+    ),
+    (
+        "fixed_bytes_test.go",
+        include_str!("../runtime/golang/aptostypes/fixed_bytes_test.go"),
+    ),
+    (
+        "frame.go",
+        include_str!("../runtime/golang/aptostypes/frame.go"),
+    ),
+    (
+        "frame_test.go",
+        include_str!("../runtime/golang/aptostypes/frame_test.go"),
+    ),
+    (
+        "fuzz_test.go",
+        include_str!("../runtime/golang/aptostypes/fuzz_test.go"),
+    ),
+    (
+        "hex.go",
+        include_str!("../runtime/golang/aptostypes/hex.go"),
+    ),
+    (
+        "hex_test.go",
+        include_str!("../runtime/golang/aptostypes/hex_test.go"),
+    ),
+    (
+        "identifier.go",
+        include_str!("../runtime/golang/aptostypes/identifier.go"),
+    ),
+    (
+        "identifier_test.go",
+        include_str!("../runtime/golang/aptostypes/identifier_test.go"),
+    ),
+    (
+        "into.go",
+        include_str!("../runtime/golang/aptostypes/into.go"),
+    ),
+    (
+        "into_test.go",
+        include_str!("../runtime/golang/aptostypes/into_test.go"),
+    ),
+    (
+        "integers.go",
+        include_str!("../runtime/golang/aptostypes/integers.go"),
+    ),
+    (
+        "integers_test.go",
+        include_str!("../runtime/golang/aptostypes/integers_test.go"),
+    ),
+    (
+        "json.go",
+        include_str!("../runtime/golang/aptostypes/json.go"),
+    ),
+    (
+        "json_test.go",
+        include_str!("../runtime/golang/aptostypes/json_test.go"),
+    ),
+    (
+        "keys_test.go",
+        include_str!("../runtime/golang/aptostypes/keys_test.go"),
+    ),
+    (
+        "length.go",
+        include_str!("../runtime/golang/aptostypes/length.go"),
+    ),
+    (
+        "length_test.go",
+        include_str!("../runtime/golang/aptostypes/length_test.go"),
+    ),
+    (
+        "lenient.go",
+        include_str!("../runtime/golang/aptostypes/lenient.go"),
+    ),
+    (
+        "lenient_test.go",
+        include_str!("../runtime/golang/aptostypes/lenient_test.go"),
+    ),
+    (
+        "maps.go",
+        include_str!("../runtime/golang/aptostypes/maps.go"),
+    ),
+    (
+        "maps_test.go",
+        include_str!("../runtime/golang/aptostypes/maps_test.go"),
+    ),
+    (
+        "metadata.go",
+        include_str!("../runtime/golang/aptostypes/metadata.go"),
+    ),
+    (
+        "metadata_test.go",
+        include_str!("../runtime/golang/aptostypes/metadata_test.go"),
+    ),
+    (
+        "multi_ed25519.go",
+        include_str!("../runtime/golang/aptostypes/multi_ed25519.go"),
+    ),
+    (
+        "multi_ed25519_test.go",
+        include_str!("../runtime/golang/aptostypes/multi_ed25519_test.go"),
+    ),
+    (
+        "option.go",
+        include_str!("../runtime/golang/aptostypes/option.go"),
+    ),
+    (
+        "option_test.go",
+        include_str!("../runtime/golang/aptostypes/option_test.go"),
+    ),
+    (
+        "raw_transaction.go",
+        include_str!("../runtime/golang/aptostypes/raw_transaction.go"),
+    ),
+    (
+        "raw_transaction_test.go",
+        include_str!("../runtime/golang/aptostypes/raw_transaction_test.go"),
+    ),
+    (
+        "reader.go",
+        include_str!("../runtime/golang/aptostypes/reader.go"),
+    ),
+    (
+        "reader_test.go",
+        include_str!("../runtime/golang/aptostypes/reader_test.go"),
+    ),
+    (
+        "sequences.go",
+        include_str!("../runtime/golang/aptostypes/sequences.go"),
+    ),
+    (
+        "sequences_test.go",
+        include_str!("../runtime/golang/aptostypes/sequences_test.go"),
+    ),
+    (
+        "serializer.go",
+        include_str!("../runtime/golang/aptostypes/serializer.go"),
+    ),
+    (
+        "serializer_test.go",
+        include_str!("../runtime/golang/aptostypes/serializer_test.go"),
+    ),
+    (
+        "signing.go",
+        include_str!("../runtime/golang/aptostypes/signing.go"),
+    ),
+    (
+        "signing_test.go",
+        include_str!("../runtime/golang/aptostypes/signing_test.go"),
+    ),
+    (
+        "type_tag.go",
+        include_str!("../runtime/golang/aptostypes/type_tag.go"),
+    ),
+    (
+        "type_tag_test.go",
+        include_str!("../runtime/golang/aptostypes/type_tag_test.go"),
+    ),
+    (
+        "vectors.go",
+        include_str!("../runtime/golang/aptostypes/vectors.go"),
+    ),
+    (
+        "vectors_test.go",
+        include_str!("../runtime/golang/aptostypes/vectors_test.go"),
+    ),
+    (
+        "writer.go",
+        include_str!("../runtime/golang/aptostypes/writer.go"),
+    ),
+    (
+        "writer_test.go",
+        include_str!("../runtime/golang/aptostypes/writer_test.go"),
+    ),
+];
+
+/// Methods implemented by every variant of the `ScriptCall` and `ScriptFunctionCall` interfaces.
+/// `{interface}` stands for the name of the interface.
+const CALL_INTERFACE_METHODS: &[&str] = &[
+    // Name of the builder, e.g. "peer_to_peer_with_metadata" or "coin_transfer".
+    "Name() string",
+    // Deep copy, sharing no slices with the original.
+    "Clone() {interface}",
+    // Field-wise comparison, where nil and empty slices are equal.
+    "Equal(other {interface}) bool",
+    // Multi-line description of the call and of its arguments, for humans.
+    "String() string",
+    // Values of the regular arguments in order, as described by `ScriptFunctionInfo.Args`.
+    "ArgValues() []interface{}",
+];
+
+/// Methods declared by the `ScriptCall` interface only.
+const SCRIPT_CALL_INTERFACE_METHODS: &[&str] = &[
+    // The encoded script, as built by `EncodeScript`.
+    "Encode() aptostypes.Script",
+];
+
+/// Methods declared by the `ScriptFunctionCall` interface only.
+const SCRIPT_FUNCTION_CALL_INTERFACE_METHODS: &[&str] = &[
+    // The encoded payload, as built by `EncodeScriptFunction`.
+    "Encode() aptostypes.TransactionPayload",
+    // Module, name and type arguments of the called function, as in the encoded payload.
+    "ModuleId() aptostypes.ModuleId",
+    "FunctionName() aptostypes.Identifier",
+    "TyArgs() []aptostypes.TypeTag",
+];
+
+/// Minor version of the first Go release with generics. Code using them is only compiled by
+/// this release and later ones when an older Go version is targeted.
+const GENERICS_GO_VERSION: u32 = 18;
+
+/// Minor version of the oldest Go release able to compile the generated code, e.g. for
+/// `errors.Is` and digit separators.
+pub const OLDEST_GO_VERSION: u32 = 13;
+
+/// How the variants of the `ScriptCall` and `ScriptFunctionCall` enums are exposed.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum EnumStyle {
+    /// An interface implemented by one struct per variant, to be used in type switches.
+    Interface,
+    /// In addition, a generic visitor interface with one method per variant, so that the
+    /// compiler checks that every variant is handled. Requires Go 1.18.
+    Visitor,
+}
+
+impl Default for EnumStyle {
+    fn default() -> Self {
+        EnumStyle::Interface
+    }
+}
+
 /// Output transaction builders and decoders in Go for the given ABIs.
+/// The code is formatted with `gofmt` if it is installed.
 pub fn output(
     out: &mut dyn Write,
     serde_module_path: Option<String>,
@@ -30,17 +337,87 @@ pub fn output(
     package_name: String,
     abis: &[ScriptABI],
 ) -> Result<()> {
+    output_with_enum_style(
+        out,
+        serde_module_path,
+        aptos_module_path,
+        package_name,
+        abis,
+        EnumStyle::default(),
+    )
+}
+
+/// Same as `output` with a choice of `EnumStyle`.
+pub fn output_with_enum_style(
+    out: &mut dyn Write,
+    serde_module_path: Option<String>,
+    aptos_module_path: Option<String>,
+    package_name: String,
+    abis: &[ScriptABI],
+    enum_style: EnumStyle,
+) -> Result<()> {
+    let mut code = Vec::new();
+    output_unformatted(
+        &mut code,
+        serde_module_path,
+        aptos_module_path,
+        package_name,
+        abis,
+        enum_style,
+    )?;
+    out.write_all(&gofmt(code)?)
+}
+
+/// Output a Go test checking that decoding the payload built by each script function encoder
+/// gives back the encoder's arguments, to go along the code written by `output`, along with
+/// a test and a benchmark of `DecoderCache`.
+pub fn output_round_trip_tests(
+    out: &mut dyn Write,
+    serde_module_path: Option<String>,
+    aptos_module_path: Option<String>,
+    package_name: String,
+    abis: &[ScriptABI],
+) -> Result<()> {
+    let mut code = Vec::new();
     let mut emitter = GoEmitter {
-        out: IndentedWriter::new(out, IndentConfig::Tab),
+        out: IndentedWriter::new(&mut code, IndentConfig::Tab),
         serde_module_path,
         aptos_module_path,
         package_name,
     };
+    emitter.output_generated_header(abis)?;
+    emitter.output_round_trip_tests(&common::script_function_abis(&supported_abis(abis)))?;
+    out.write_all(&gofmt(code)?)
+}
+
+/// Output the visitors of `EnumStyle::Visitor` alone, for Go 1.18 and later only, to go along
+/// the code written by `output` with `EnumStyle::Interface`.
+fn output_visitors_file(
+    out: &mut dyn Write,
+    package_name: String,
+    abis: &[ScriptABI],
+) -> Result<()> {
+    let mut code = Vec::new();
+    let mut emitter = GoEmitter {
+        out: IndentedWriter::new(&mut code, IndentConfig::Tab),
+        serde_module_path: None,
+        aptos_module_path: None,
+        package_name,
+    };
+    emitter.output_generated_header(abis)?;
+    writeln!(
+        emitter.out,
+        "//go:build go1.{}\n\npackage {}\n\nimport \"fmt\"",
+        GENERICS_GO_VERSION, emitter.package_name
+    )?;
+    emitter.output_visitors(&supported_abis(abis))?;
+    out.write_all(&gofmt(code)?)
+}
 
-    // Some functions have complex types which are not currently supported in bcs or in this
-    // generator. Disable those functiosn for now.
-    let abis_vec = abis
-        .iter()
+/// Some functions have complex types which are not currently supported in bcs or in this
+/// generator. Disable those functions for now.
+fn supported_abis(abis: &[ScriptABI]) -> Vec<ScriptABI> {
+    abis.iter()
         .cloned()
         .filter(|abi| {
             if let ScriptABI::ScriptFunction(sf) = abi {
@@ -50,12 +427,87 @@ pub fn output(
                 true
             }
         })
-        .collect::<Vec<_>>();
+        .collect()
+}
 
+/// SHA3-256 of the BCS bytes of the ABIs, so that regenerating from different framework
+/// sources changes the header of the generated file.
+fn abi_hash(abis: &[ScriptABI]) -> Result<HashValue> {
+    let bytes = bcs::to_bytes(abis)
+        .map_err(|err| std::io::Error::new(ErrorKind::Other, format!("{}", err)))?;
+    Ok(HashValue::sha3_256_of(&bytes))
+}
+
+/// Format Go code with `gofmt`, like `go/format.Source` does. If `gofmt` is not installed,
+/// the code is returned unchanged, with a warning on stderr since it is not gofmt-clean.
+pub fn gofmt(code: Vec<u8>) -> Result<Vec<u8>> {
+    let mut child = match Command::new("gofmt")
+        .stdin(Stdio::piped())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+    {
+        Ok(child) => child,
+        Err(err) if err.kind() == ErrorKind::NotFound => {
+            static WARNING: Once = Once::new();
+            WARNING.call_once(|| {
+                eprintln!(
+                    "warning: gofmt was not found in PATH, the generated Go code is not \
+                     formatted; install Go to get gofmt-clean code"
+                )
+            });
+            return Ok(code);
+        }
+        Err(err) => return Err(err),
+    };
+    let mut stdin = child.stdin.take().expect("stdin is piped");
+    let writer = std::thread::spawn(move || stdin.write_all(&code));
+    let output = child.wait_with_output()?;
+    writer.join().expect("writer thread should not panic")?;
+    if !output.status.success() {
+        return Err(std::io::Error::new(
+            ErrorKind::Other,
+            format!("gofmt failed: {}", String::from_utf8_lossy(&output.stderr)),
+        ));
+    }
+    Ok(output.stdout)
+}
+
+fn output_unformatted(
+    out: &mut dyn Write,
+    serde_module_path: Option<String>,
+    aptos_module_path: Option<String>,
+    package_name: String,
+    abis: &[ScriptABI],
+    enum_style: EnumStyle,
+) -> Result<()> {
+    let mut emitter = GoEmitter {
+        out: IndentedWriter::new(out, IndentConfig::Tab),
+        serde_module_path,
+        aptos_module_path,
+        package_name,
+    };
+    emitter.output_generated_header(abis)?;
+
+    let abis_vec = supported_abis(abis);
     let abis = abis_vec.as_slice();
     emitter.output_script_call_enum_with_imports(abis)?;
+    emitter.output_name_methods(abis)?;
+    emitter.output_function_id_methods(&common::script_function_abis(abis))?;
+    emitter.output_clone_methods(abis)?;
+    emitter.output_equal_methods(abis)?;
+    emitter.output_as_functions(abis)?;
+    emitter.output_arg_values_methods(abis)?;
+    emitter.output_script_infos(abis)?;
+    emitter.output_json_methods(&common::script_function_abis(abis))?;
+    emitter.output_string_methods(abis)?;
+    if enum_style == EnumStyle::Visitor {
+        emitter.output_visitors(abis)?;
+    }
 
     emitter.output_encode_method(abis)?;
+    emitter.output_encode_methods(abis)?;
+    emitter.output_decoding_errors()?;
     emitter.output_transaction_script_decode_method()?;
     emitter.output_script_function_decode_method()?;
 
@@ -85,10 +537,14 @@ pub fn output(
         emitter.output_code_constant(abi)?;
     }
     emitter.output_transaction_script_decoder_map(&common::transaction_script_abis(abis))?;
+    emitter.output_transaction_script_validation(&common::transaction_script_abis(abis))?;
     emitter.output_script_function_decoder_map(&common::script_function_abis(abis))?;
+    emitter.output_decoder_registry(&common::script_function_abis(abis))?;
+    emitter.output_decoder_cache()?;
 
     emitter.output_encoding_helpers(abis)?;
     emitter.output_decoding_helpers(&common::filter_transaction_scripts(abis))?;
+    emitter.output_bcs_decoding_helpers(&common::script_function_abis(abis))?;
 
     Ok(())
 }
@@ -111,6 +567,18 @@ impl<T> GoEmitter<T>
 where
     T: Write,
 {
+    /// Mark the file as generated, following the convention of `go generate`, and record
+    /// the version of this generator and the hash of the input ABIs.
+    fn output_generated_header(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        writeln!(
+            self.out,
+            "// Code generated by transaction-builder-generator {}; DO NOT EDIT.",
+            env!("CARGO_PKG_VERSION"),
+        )?;
+        writeln!(self.out, "// ABI hash: {}", abi_hash(abis)?.to_hex())?;
+        writeln!(self.out)
+    }
+
     fn output_script_call_enum_with_imports(&mut self, abis: &[ScriptABI]) -> Result<()> {
         let aptos_types_package = match &self.aptos_module_path {
             Some(path) => format!("{}/aptostypes", path),
@@ -124,7 +592,22 @@ where
             Vec::new(),
         );
         // Add standard imports
+        if abis.iter().any(|abi| {
+            abi.args()
+                .iter()
+                .any(|arg| arg.type_tag() == &TypeTag::Vector(Box::new(TypeTag::U8)))
+        }) {
+            external_definitions.insert("bytes".to_string(), Vec::new());
+        }
+        if abis.iter().any(|abi| !abi.is_transaction_script_abi()) {
+            external_definitions.insert("encoding/json".to_string(), Vec::new());
+        }
+        external_definitions.insert("container/list".to_string(), Vec::new());
+        external_definitions.insert("crypto/sha256".to_string(), Vec::new());
+        external_definitions.insert("errors".to_string(), Vec::new());
         external_definitions.insert("fmt".to_string(), Vec::new());
+        external_definitions.insert("strings".to_string(), Vec::new());
+        external_definitions.insert("sync".to_string(), Vec::new());
 
         let (transaction_script_abis, script_fun_abis): (Vec<_>, Vec<_>) = abis
             .iter()
@@ -155,12 +638,8 @@ where
                 (
                     vec![
                         self.package_name.to_string(),
-                        if abi.is_transaction_script_abi() {
-                            "ScriptCall".to_string()
-                        } else {
-                            "ScriptFunctionCall".to_string()
-                        },
-                        abi.name().to_camel_case(),
+                        Self::interface_name(abi).to_string(),
+                        Self::variant_name(abi),
                     ],
                     crate::common::prepare_doc_string(abi.doc()),
                 )
@@ -189,9 +668,487 @@ where
         if let Some(path) = &self.serde_module_path {
             generator = generator.with_serde_module_path(path.clone());
         }
+        let mut code = Vec::new();
         generator
-            .output(&mut self.out, &script_registry)
+            .output(&mut code, &script_registry)
+            .map_err(|err| std::io::Error::new(std::io::ErrorKind::Other, format!("{}", err)))?;
+        let code = String::from_utf8(code)
             .map_err(|err| std::io::Error::new(std::io::ErrorKind::Other, format!("{}", err)))?;
+        self.out
+            .write_all(Self::extend_call_interfaces(code).as_bytes())
+    }
+
+    /// The interfaces generated by serde-generate only declare a private marker method.
+    /// Declare the methods that we implement on every variant as well.
+    fn extend_call_interfaces(code: String) -> String {
+        let mut code = code;
+        for name in &["ScriptCall", "ScriptFunctionCall"] {
+            let marker = format!("\tis{}()\n", name);
+            let specific_methods = match *name {
+                "ScriptFunctionCall" => SCRIPT_FUNCTION_CALL_INTERFACE_METHODS,
+                "ScriptCall" => SCRIPT_CALL_INTERFACE_METHODS,
+                _ => &[],
+            };
+            let methods: String = CALL_INTERFACE_METHODS
+                .iter()
+                .chain(specific_methods)
+                .map(|method| format!("\t{}\n", method.replace("{interface}", name)))
+                .collect();
+            code = code.replacen(&marker, &format!("{}{}", marker, methods), 1);
+        }
+        code
+    }
+
+    fn output_name_methods(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        writeln!(self.out)?;
+        for abi in abis {
+            match abi {
+                ScriptABI::TransactionScript(script) => writeln!(
+                    self.out,
+                    "func (*ScriptCall__{}) Name() string {{ return \"{}\" }}",
+                    Self::variant_name(abi),
+                    script.name(),
+                )?,
+                ScriptABI::ScriptFunction(function) => writeln!(
+                    self.out,
+                    "func (*ScriptFunctionCall__{}) Name() string {{ return \"{}_{}\" }}",
+                    Self::variant_name(abi),
+                    function.module_name().name(),
+                    function.name(),
+                )?,
+            }
+        }
+        Ok(())
+    }
+
+    fn output_function_id_methods(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+        for abi in abis {
+            let variant = format!(
+                "ScriptFunctionCall__{}",
+                Self::script_function_variant_name(abi)
+            );
+            let ty_args = abi
+                .ty_args()
+                .iter()
+                .map(|ty_arg| format!("call.{}", ty_arg.name().to_camel_case()))
+                .collect::<Vec<_>>()
+                .join(", ");
+            writeln!(
+                self.out,
+                r#"
+func (*{0}) ModuleId() aptostypes.ModuleId {{
+	return {1}
+}}
+
+func (*{0}) FunctionName() aptostypes.Identifier {{ return {2} }}
+
+func (call *{0}) TyArgs() []aptostypes.TypeTag {{
+	return []aptostypes.TypeTag{{{3}}}
+}}"#,
+                variant,
+                Self::quote_module_id(abi.module_name()),
+                Self::quote_identifier(abi.name()),
+                ty_args,
+            )?;
+        }
+        Ok(())
+    }
+
+    fn output_clone_methods(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        for abi in abis {
+            writeln!(
+                self.out,
+                "\nfunc (call *{0}__{1}) Clone() {0} {{\n\tclone := *call",
+                Self::interface_name(abi),
+                Self::variant_name(abi)
+            )?;
+            self.out.indent();
+            for ty_arg in abi.ty_args() {
+                writeln!(
+                    self.out,
+                    "clone.{0} = aptostypes.CloneTypeTag(call.{0})",
+                    ty_arg.name().to_camel_case()
+                )?;
+            }
+            for arg in abi.args() {
+                let field = arg.name().to_camel_case();
+                if Self::is_bytes_vector(arg.type_tag()) {
+                    writeln!(
+                        self.out,
+                        "clone.{0} = aptostypes.CloneBytesVector(call.{0})",
+                        field
+                    )?;
+                } else if let TypeTag::Vector(_) = arg.type_tag() {
+                    writeln!(
+                        self.out,
+                        "clone.{0} = append({1}(nil), call.{0}...)",
+                        field,
+                        Self::quote_type(arg.type_tag())
+                    )?;
+                }
+            }
+            writeln!(self.out, "return &clone")?;
+            self.out.unindent();
+            writeln!(self.out, "}}")?;
+        }
+        Ok(())
+    }
+
+    fn output_equal_methods(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        for abi in abis {
+            let (interface, variant) = (Self::interface_name(abi), Self::variant_name(abi));
+            let mut conditions = vec!["ok".to_string()];
+            for ty_arg in abi.ty_args() {
+                conditions.push(format!(
+                    "aptostypes.EqualTypeTag(call.{0}, o.{0})",
+                    ty_arg.name().to_camel_case()
+                ));
+            }
+            for arg in abi.args() {
+                let field = arg.name().to_camel_case();
+                conditions.push(match arg.type_tag() {
+                    TypeTag::Vector(type_tag) if type_tag.as_ref() == &TypeTag::U8 => {
+                        format!("bytes.Equal(call.{0}, o.{0})", field)
+                    }
+                    type_tag => match Self::vector_helper_name(type_tag) {
+                        Some(name) => {
+                            format!("aptostypes.Equal{1}(call.{0}, o.{0})", field, name)
+                        }
+                        None => format!("call.{0} == o.{0}", field),
+                    },
+                });
+            }
+            writeln!(
+                self.out,
+                "\nfunc (call *{0}__{1}) Equal(other {0}) bool {{\n\to, ok := other.(*{0}__{1})",
+                interface, variant
+            )?;
+            self.out.indent();
+            writeln!(self.out, "return {}", conditions.join(" &&\n\t"))?;
+            self.out.unindent();
+            writeln!(self.out, "}}")?;
+        }
+        Ok(())
+    }
+
+    /// Checked conversions from the call interfaces, so that callers need not write type
+    /// assertions which panic on unexpected variants.
+    fn output_as_functions(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        for abi in abis {
+            let (interface, variant) = (Self::interface_name(abi), Self::variant_name(abi));
+            writeln!(
+                self.out,
+                r#"
+// As{1} returns `call` as a `{0}__{1}`, if it is one.
+func As{1}(call {0}) (*{0}__{1}, bool) {{
+	value, ok := call.(*{0}__{1})
+	return value, ok
+}}"#,
+                interface, variant
+            )?;
+        }
+        Ok(())
+    }
+
+    fn output_arg_values_methods(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        for abi in abis {
+            let (interface, variant) = (Self::interface_name(abi), Self::variant_name(abi));
+            let values = abi
+                .args()
+                .iter()
+                .map(|arg| format!("call.{}", arg.name().to_camel_case()))
+                .collect::<Vec<_>>()
+                .join(", ");
+            writeln!(
+                self.out,
+                "\nfunc (call *{0}__{1}) ArgValues() []interface{{}} {{\n\treturn []interface{{}}{{{2}}}\n}}",
+                interface, variant, values
+            )?;
+        }
+        Ok(())
+    }
+
+    /// Descriptors of the calls, e.g. for user interfaces offering all of them.
+    fn output_script_infos(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+// ScriptFunctionInfo describes a call supported by this package, as given by its ABI, e.g. to
+// build a form for it.
+type ScriptFunctionInfo struct {{
+	// Name is the name returned by the `Name` method of the call, e.g. "coin_transfer".
+	Name string
+	// Module and Function identify the script function called. Module is nil for
+	// transaction scripts.
+	Module   *aptostypes.ModuleId
+	Function aptostypes.Identifier
+	// TypeParams are the names of the type parameters, in order.
+	TypeParams []string
+	Args       []ScriptArgumentInfo
+}}
+
+// ScriptArgumentInfo describes an argument of a call.
+type ScriptArgumentInfo struct {{
+	Name string
+	// Type is the Move type of the argument, e.g. "u64" or "vector<u8>".
+	Type string
+}}
+
+// FunctionId returns the ID of the script function called, e.g. "0x1::coin::transfer", or
+// the empty string for transaction scripts.
+func (info *ScriptFunctionInfo) FunctionId() string {{
+	if info.Module == nil {{
+		return ""
+	}}
+	return info.Module.String() + "::" + string(info.Function)
+}}
+
+// ArgNames returns the names of the arguments, in the order of the values returned by
+// `ArgValues`.
+func (info *ScriptFunctionInfo) ArgNames() []string {{
+	names := make([]string, len(info.Args))
+	for i, arg := range info.Args {{
+		names[i] = arg.Name
+	}}
+	return names
+}}
+
+// AllScriptFunctions describes all the calls supported by this package. The result is
+// allocated on every call and may be modified.
+func AllScriptFunctions() []ScriptFunctionInfo {{
+	return []ScriptFunctionInfo{{"#
+        )?;
+        self.out.indent();
+        self.out.indent();
+        for abi in abis {
+            writeln!(self.out, "{{")?;
+            self.out.indent();
+            match abi {
+                ScriptABI::TransactionScript(abi) => {
+                    writeln!(self.out, "Name: {},", Self::quote_identifier(abi.name()))?;
+                }
+                ScriptABI::ScriptFunction(abi) => {
+                    let name = format!("{}_{}", abi.module_name().name(), abi.name());
+                    writeln!(self.out, "Name: {},", Self::quote_identifier(&name))?;
+                    writeln!(
+                        self.out,
+                        "Module: &{},",
+                        Self::quote_module_id(abi.module_name())
+                    )?;
+                    writeln!(
+                        self.out,
+                        "Function: {},",
+                        Self::quote_identifier(abi.name())
+                    )?;
+                }
+            }
+            writeln!(
+                self.out,
+                "TypeParams: []string{{{}}},",
+                abi.ty_args()
+                    .iter()
+                    .map(|ty_arg| Self::quote_identifier(ty_arg.name()))
+                    .collect::<Vec<_>>()
+                    .join(", ")
+            )?;
+            writeln!(self.out, "Args: []ScriptArgumentInfo{{")?;
+            self.out.indent();
+            for arg in abi.args() {
+                writeln!(
+                    self.out,
+                    "{{Name: {}, Type: \"{}\"}},",
+                    Self::quote_identifier(arg.name()),
+                    Self::quote_move_type(arg.type_tag())
+                )?;
+            }
+            self.out.unindent();
+            writeln!(self.out, "}},")?;
+            self.out.unindent();
+            writeln!(self.out, "}},")?;
+        }
+        self.out.unindent();
+        writeln!(self.out, "}}")?;
+        self.out.unindent();
+        writeln!(self.out, "}}")
+    }
+
+    /// JSON form of script function calls, in the shape of the REST API.
+    fn output_json_methods(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+        if abis.is_empty() {
+            return Ok(());
+        }
+        writeln!(
+            self.out,
+            r#"
+// MarshalPayloadJSON returns the JSON form of `payload` used by the REST API. The arguments of
+// known script functions are converted to their natural JSON values, see
+// `aptostypes.ArgumentJSON`; other payloads are marshaled as is.
+func MarshalPayloadJSON(payload aptostypes.TransactionPayload) ([]byte, error) {{
+	if call, err := DecodeScriptFunctionPayload(payload); err == nil {{
+		return json.Marshal(call)
+	}}
+	return json.Marshal(payload)
+}}
+
+func marshalScriptFunctionCallJSON(call ScriptFunctionCall, arguments ...interface{{}}) ([]byte, error) {{
+	function := aptostypes.ScriptFunction{{
+		Module:   call.ModuleId(),
+		Function: call.FunctionName(),
+		TyArgs:   call.TyArgs(),
+	}}
+	if arguments == nil {{
+		arguments = []interface{{}}{{}}
+	}}
+	return json.Marshal(aptostypes.NewScriptFunctionPayloadJSON(&function, arguments))
+}}"#
+        )?;
+        for abi in abis {
+            let arguments: String = abi
+                .args()
+                .iter()
+                .map(|arg| {
+                    format!(
+                        ", aptostypes.ArgumentJSON(call.{})",
+                        arg.name().to_camel_case()
+                    )
+                })
+                .collect();
+            writeln!(
+                self.out,
+                "\nfunc (call *ScriptFunctionCall__{}) MarshalJSON() ([]byte, error) {{\n\treturn marshalScriptFunctionCallJSON(call{})\n}}",
+                Self::script_function_variant_name(abi),
+                arguments,
+            )?;
+        }
+        Ok(())
+    }
+
+    fn output_string_methods(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        for abi in abis {
+            let (interface, variant) = (Self::interface_name(abi), Self::variant_name(abi));
+            let (function, ty_args, args) = match abi {
+                ScriptABI::TransactionScript(abi) => {
+                    (format!("\"{}\"", abi.name()), abi.ty_args(), abi.args())
+                }
+                // With the short address of `ModuleId.String`, as in `FunctionId`.
+                ScriptABI::ScriptFunction(abi) => (
+                    format!("call.ModuleId().String() + \"::{}\"", abi.name()),
+                    abi.ty_args(),
+                    abi.args(),
+                ),
+            };
+            writeln!(
+                self.out,
+                "\nfunc (call *{}__{}) String() string {{\n\tvar b strings.Builder",
+                interface, variant
+            )?;
+            self.out.indent();
+            writeln!(self.out, "b.WriteString({})", function)?;
+            if !ty_args.is_empty() {
+                writeln!(
+                    self.out,
+                    "fmt.Fprintf(&b, \"<{}>\", {})",
+                    vec!["%v"; ty_args.len()].join(", "),
+                    ty_args
+                        .iter()
+                        .map(|ty_arg| format!("call.{}", ty_arg.name().to_camel_case()))
+                        .collect::<Vec<_>>()
+                        .join(", ")
+                )?;
+            }
+            for arg in args {
+                let (verb, value) = Self::quote_value_format(
+                    arg.type_tag(),
+                    &format!("call.{}", arg.name().to_camel_case()),
+                );
+                writeln!(
+                    self.out,
+                    "fmt.Fprintf(&b, \"\\n  {}: {}\", {})",
+                    arg.name(),
+                    verb,
+                    value
+                )?;
+            }
+            writeln!(self.out, "return b.String()")?;
+            self.out.unindent();
+            writeln!(self.out, "}}")?;
+        }
+        Ok(())
+    }
+
+    fn output_visitors(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        let variants = |is_script: bool| -> Vec<String> {
+            abis.iter()
+                .filter(|abi| abi.is_transaction_script_abi() == is_script)
+                .map(Self::variant_name)
+                .collect()
+        };
+        let script_variants = variants(true);
+        if !script_variants.is_empty() {
+            self.output_visitor("ScriptCall", &script_variants)?;
+        }
+        self.output_visitor("ScriptFunctionCall", &variants(false))
+    }
+
+    fn output_visitor(&mut self, name: &str, variants: &[String]) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+// {0}Visitor handles every variant of `{0}`. Implementations are checked by the compiler
+// to be exhaustive when passed to `Visit{0}`.
+type {0}Visitor[R any] interface {{"#,
+            name
+        )?;
+        self.out.indent();
+        for variant in variants {
+            writeln!(
+                self.out,
+                "Visit{1}(call *{0}__{1}) (R, error)",
+                name, variant
+            )?;
+        }
+        self.out.unindent();
+        writeln!(
+            self.out,
+            r#"}}
+
+// Visit{0} calls the method of `visitor` handling the variant of `call`.
+func Visit{0}[R any](call {0}, visitor {0}Visitor[R]) (R, error) {{
+	switch call := call.(type) {{"#,
+            name
+        )?;
+        for variant in variants {
+            writeln!(
+                self.out,
+                "\tcase *{0}__{1}:\n\t\treturn visitor.Visit{1}(call)",
+                name, variant
+            )?;
+        }
+        writeln!(
+            self.out,
+            r#"	}}
+	var zero R
+	return zero, fmt.Errorf("%w of {0}: %T", ErrUnknownVariant, call)
+}}"#,
+            name
+        )
+    }
+
+    fn output_encode_methods(&mut self, abis: &[ScriptABI]) -> Result<()> {
+        for abi in abis {
+            match abi {
+                ScriptABI::TransactionScript(_) => writeln!(
+                    self.out,
+                    "\nfunc (call *ScriptCall__{}) Encode() aptostypes.Script {{\n\treturn EncodeScript(call)\n}}",
+                    Self::variant_name(abi)
+                )?,
+                ScriptABI::ScriptFunction(_) => writeln!(
+                    self.out,
+                    "\nfunc (call *ScriptFunctionCall__{}) Encode() aptostypes.TransactionPayload {{\n\treturn EncodeScriptFunction(call)\n}}",
+                    Self::variant_name(abi)
+                )?,
+            }
+        }
         Ok(())
     }
 
@@ -252,10 +1209,9 @@ func EncodeScriptFunction(call ScriptFunctionCall) aptostypes.TransactionPayload
                         .join(", ");
                     writeln!(
                         self.out,
-                        r#"case *ScriptFunctionCall__{0}{1}:
-                return Encode{0}{1}({2})"#,
-                        abi.module_name().name().to_string().to_camel_case(),
-                        abi.name().to_camel_case(),
+                        r#"case *ScriptFunctionCall__{0}:
+                return Encode{0}({1})"#,
+                        Self::script_function_variant_name(&abi),
                         params,
                     )?;
                 }
@@ -264,22 +1220,93 @@ func EncodeScriptFunction(call ScriptFunctionCall) aptostypes.TransactionPayload
             writeln!(self.out, "panic(\"unreachable\")")?;
             self.out.unindent();
             writeln!(self.out, "}}")?;
+
+            writeln!(
+                self.out,
+                r#"
+// Build an Aptos `TransactionPayload` from a structured object `ScriptFunctionCall` after
+// checking that its type arguments are set and that their Move identifiers are valid.
+func EncodeScriptFunctionValidated(call ScriptFunctionCall) (aptostypes.TransactionPayload, error) {{
+	payload := EncodeScriptFunction(call)
+	if err := payload.(*aptostypes.TransactionPayload__ScriptFunction).Value.Validate(); err != nil {{
+		return nil, err
+	}}
+	return payload, nil
+}}
+
+// Serialize the payloads of the given calls back to back, reusing the capacity of `buf`.
+// The returned slices share a single buffer, which amortizes allocations over large batches.
+func BcsSerializeScriptFunctionBatch(buf []byte, calls []ScriptFunctionCall) ([][]byte, error) {{
+	buf = buf[:0]
+	ends := make([]int, len(calls))
+	for i, call := range calls {{
+		var err error
+		if buf, err = aptostypes.AppendBcs(buf, EncodeScriptFunction(call)); err != nil {{
+			return nil, err
+		}}
+		ends[i] = len(buf)
+	}}
+	payloads := make([][]byte, len(calls))
+	start := 0
+	for i, end := range ends {{
+		payloads[i] = buf[start:end:end]
+		start = end
+	}}
+	return payloads, nil
+}}"#
+            )?;
         }
         Ok(())
     }
 
+    fn output_decoding_errors(&mut self) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+// Errors wrapped by the decoding functions of this package, to be tested with `errors.Is`.
+// Malformed arguments are reported with the errors of `aptostypes.Deserializer`, e.g.
+// `aptostypes.ErrTruncated` or `aptostypes.ErrNonCanonical`.
+var (
+	// ErrUnknownScript is returned by `DecodeScript` and `DecodeScriptFunctionPayload` for
+	// scripts and script functions without a decoder in this package.
+	ErrUnknownScript = errors.New("Unknown script")
+	// ErrUnknownVariant is returned by `DecodeScriptFunction`, `DecodeScriptFunctionPayload`
+	// and `DecoderRegistry.Decode` for payloads other than script functions. It is the same
+	// error as `aptostypes.ErrUnknownVariant`.
+	ErrUnknownVariant = aptostypes.ErrUnknownVariant
+)"#
+        )
+    }
+
     fn output_transaction_script_decode_method(&mut self) -> Result<()> {
         writeln!(
             self.out,
             r#"
 // Try to recognize an Aptos `Script` and convert it into a structured object `ScriptCall`.
-func DecodeScript(script *aptostypes.Script) (ScriptCall, error) {{
+func DecodeScript(script *aptostypes.Script) (call ScriptCall, err error) {{
+	defer recoverDecodingPanic(&err)
+	if script == nil {{
+		return nil, fmt.Errorf("Unexpected nil script encountered when decoding")
+	}}
 	if helper := script_decoder_map[string(script.Code)]; helper != nil {{
 		val, err := helper(script)
                 return val, err
 	}} else {{
-		return nil, fmt.Errorf("Unknown script bytecode: %s", string(script.Code))
+		return nil, fmt.Errorf("%w bytecode: %s", ErrUnknownScript, string(script.Code))
+	}}
+}}
+
+// Like `DecodeScript`, but return the raw `*aptostypes.Script`, with its code, type arguments
+// and `TransactionArgument`s, instead of an error when the script is unknown or when its
+// arguments do not match the current ABI. Only nil scripts are rejected.
+func DecodeScriptOrRaw(script *aptostypes.Script) (interface{{}}, error) {{
+	if script == nil {{
+		return nil, fmt.Errorf("Unexpected nil script encountered when decoding")
+	}}
+	if call, err := DecodeScript(script); err == nil {{
+		return call, nil
 	}}
+	return script, nil
 }}"#
         )
     }
@@ -288,19 +1315,82 @@ func DecodeScript(script *aptostypes.Script) (ScriptCall, error) {{
         writeln!(
             self.out,
             r#"
+// Extract the `ScriptFunction` of an Aptos `TransactionPayload` without decoding its arguments.
+// This works for any module, including those unknown to this package.
+func DecodeScriptFunction(payload aptostypes.TransactionPayload) (*aptostypes.ScriptFunction, error) {{
+	switch payload := payload.(type) {{
+	case *aptostypes.TransactionPayload__ScriptFunction:
+		if payload == nil {{
+			return nil, fmt.Errorf("Unexpected nil TransactionPayload encountered when decoding")
+		}}
+		return &payload.Value, nil
+	default:
+		return nil, fmt.Errorf("%w of TransactionPayload: %T", ErrUnknownVariant, payload)
+	}}
+}}
+
 // Try to recognize an Aptos `TransactionPayload` and convert it into a structured object `ScriptFunctionCall`.
-func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (ScriptFunctionCall, error) {{
-    switch script := script.(type) {{
-        case *aptostypes.TransactionPayload__ScriptFunction:
-            if helper := script_function_decoder_map[string(script.Value.Module.Name) + "_" + string(script.Value.Function)]; helper != nil {{
-                    val, err := helper(script)
-                    return val, err
-            }} else {{
-                    return nil, fmt.Errorf("Unknown script function: %s::%s", script.Value.Module.Name, script.Value.Function)
-            }}
-        default:
-                return nil, fmt.Errorf("Unknown transaction payload encountered when decoding")
-    }}
+// The function is recognized by its module, address included, and its name.
+func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (call ScriptFunctionCall, err error) {{
+	defer recoverDecodingPanic(&err)
+	function, err := DecodeScriptFunction(script)
+	if err != nil {{
+		return nil, err
+	}}
+	if helper := script_function_decoder_map[scriptFunctionKey{{function.Module, function.Function}}]; helper != nil {{
+		val, err := helper(script)
+		return val, err
+	}} else {{
+		return nil, fmt.Errorf("%w function: %v::%s", ErrUnknownScript, function.Module, function.Function)
+	}}
+}}
+
+// Like `DecodeScriptFunctionPayload`, but return the raw `*aptostypes.ScriptFunction` instead
+// of an error when the function is unknown or when its arguments do not match the current
+// ABI, e.g. for historical calls made against an older framework. Only payloads which are not
+// script functions are rejected.
+func DecodeScriptFunctionPayloadOrRaw(payload aptostypes.TransactionPayload) (interface{{}}, error) {{
+	function, err := DecodeScriptFunction(payload)
+	if err != nil {{
+		return nil, err
+	}}
+	if call, err := DecodeScriptFunctionPayload(payload); err == nil {{
+		return call, nil
+	}}
+	return function, nil
+}}
+
+// Describe a script function call without decoding its arguments, e.g. to show calls to
+// modules unknown to this package. The map has the keys of the REST API: "function", the
+// function name qualified by its `ModuleId.String`, "type_arguments", a `[]string` of
+// canonical type names, and "arguments", a `[]string` of the hex-encoded BCS bytes of the
+// arguments.
+func DecodeToMap(payload aptostypes.TransactionPayload) (map[string]interface{{}}, error) {{
+	function, err := DecodeScriptFunction(payload)
+	if err != nil {{
+		return nil, err
+	}}
+	tyArgs := make([]string, len(function.TyArgs))
+	for i, tag := range function.TyArgs {{
+		tyArgs[i] = fmt.Sprint(tag)
+	}}
+	args := make([]string, len(function.Args))
+	for i, arg := range function.Args {{
+		args[i] = aptostypes.ToHex(arg)
+	}}
+	return map[string]interface{{}}{{
+		"function":       function.Module.String() + "::" + string(function.Function),
+		"type_arguments": tyArgs,
+		"arguments":      args,
+	}}, nil
+}}
+
+// Turn a panic while decoding untrusted input into an error, as a last line of defense.
+// This must be deferred directly by the public decoding functions.
+func recoverDecodingPanic(err *error) {{
+	if r := recover(); r != nil {{
+		*err = fmt.Errorf("Panic while decoding: %v", r)
+	}}
 }}"#
         )
     }
@@ -340,10 +1430,9 @@ func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (ScriptFu
     fn output_script_function_encoder_function(&mut self, abi: &ScriptFunctionABI) -> Result<()> {
         writeln!(
             self.out,
-            "\n{}\nfunc Encode{}{}({}) aptostypes.TransactionPayload {{",
+            "\n{}\nfunc Encode{}({}) aptostypes.TransactionPayload {{",
             Self::quote_doc(abi.doc()),
-            abi.module_name().name().to_string().to_camel_case(),
-            abi.name().to_camel_case(),
+            Self::script_function_variant_name(abi),
             [
                 Self::quote_type_parameters(abi.ty_args()),
                 Self::quote_parameters(abi.args()),
@@ -383,12 +1472,12 @@ func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (ScriptFu
         self.out.indent();
         writeln!(
             self.out,
-            "if len(script.TyArgs) < {0} {{ return nil, fmt.Errorf(\"Was expecting {0} type arguments\") }}",
+            "if len(script.TyArgs) != {0} {{ return nil, fmt.Errorf(\"Was expecting {0} type arguments, got %d\", len(script.TyArgs)) }}",
             abi.ty_args().len(),
         )?;
         writeln!(
             self.out,
-            "if len(script.Args) < {0} {{ return nil, fmt.Errorf(\"Was expecting {0} regular arguments\") }}",
+            "if len(script.Args) != {0} {{ return nil, fmt.Errorf(\"Was expecting {0} regular arguments, got %d\", len(script.Args)) }}",
             abi.args().len(),
         )?;
         writeln!(
@@ -441,19 +1530,22 @@ func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (ScriptFu
         self.out.indent();
         writeln!(
             self.out,
-            "if len(script.Value.TyArgs) < {0} {{ return nil, fmt.Errorf(\"Was expecting {0} type arguments\") }}",
+            "if script == nil {{ return nil, fmt.Errorf(\"Unexpected nil TransactionPayload encountered when decoding a script function\") }}",
+        )?;
+        writeln!(
+            self.out,
+            "if len(script.Value.TyArgs) != {0} {{ return nil, fmt.Errorf(\"Was expecting {0} type arguments, got %d\", len(script.Value.TyArgs)) }}",
             abi.ty_args().len(),
         )?;
         writeln!(
             self.out,
-            "if len(script.Value.Args) < {0} {{ return nil, fmt.Errorf(\"Was expecting {0} regular arguments\") }}",
+            "if len(script.Value.Args) != {0} {{ return nil, fmt.Errorf(\"Was expecting {0} regular arguments, got %d\", len(script.Value.Args)) }}",
             abi.args().len(),
         )?;
         writeln!(
             self.out,
-            "var call ScriptFunctionCall__{0}{1}",
-            abi.module_name().name().to_string().to_camel_case(),
-            abi.name().to_camel_case(),
+            "var call ScriptFunctionCall__{}",
+            Self::script_function_variant_name(abi),
         )?;
         for (index, ty_arg) in abi.ty_args().iter().enumerate() {
             writeln!(
@@ -465,6 +1557,11 @@ func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (ScriptFu
         }
         for (index, arg) in abi.args().iter().enumerate() {
             let decoding = match Self::bcs_primitive_type_name(arg.type_tag()) {
+                None if Self::vector_helper_name(arg.type_tag()).is_some() => format!(
+                    "aptostypes.BcsDeserialize{}(script.Value.Args[{}])",
+                    Self::vector_helper_name(arg.type_tag()).unwrap(),
+                    index
+                ),
                 None => {
                     let quoted_type = Self::quote_type(arg.type_tag());
                     let splits: Vec<_> = quoted_type.rsplitn(2, '.').collect();
@@ -478,9 +1575,10 @@ func DecodeScriptFunctionPayload(script aptostypes.TransactionPayload) (ScriptFu
                         left, right, index
                     )
                 }
-                Some(type_name) => format!(
-                    "bcs.NewDeserializer(script.Value.Args[{}]).Deserialize{}()",
-                    index, type_name
+                Some(_) => format!(
+                    "decode_{}_bcs_argument(script.Value.Args[{}])",
+                    common::mangle_type(arg.type_tag()),
+                    index
                 ),
             };
             writeln!(
@@ -501,7 +1599,7 @@ if val, err := {}; err == nil {{
         writeln!(
             self.out,
             r#"default:
-    return nil, fmt.Errorf("Unexpected TransactionPayload encountered when decoding a script function")"#
+    return nil, fmt.Errorf("%w of TransactionPayload: %T", ErrUnknownVariant, script)"#
         )?;
 
         self.out.unindent();
@@ -522,29 +1620,744 @@ var script_decoder_map = map[string]func(*aptostypes.Script) (ScriptCall, error)
         )?;
         self.out.indent();
         for abi in abis {
-            writeln!(self.out, "string({0}_code): decode_{0},", abi.name(),)?;
+            writeln!(self.out, "string({0}_code): decode_{0},", abi.name(),)?;
+        }
+        self.out.unindent();
+        writeln!(self.out, "}}")
+    }
+
+    fn output_transaction_script_validation(
+        &mut self,
+        abis: &[TransactionScriptABI],
+    ) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+var script_ty_arg_count_map = map[string]int {{"#
+        )?;
+        self.out.indent();
+        for abi in abis {
+            writeln!(
+                self.out,
+                "string({}_code): {},",
+                abi.name(),
+                abi.ty_args().len()
+            )?;
+        }
+        self.out.unindent();
+        writeln!(
+            self.out,
+            r#"}}
+
+// Check that a `Script` running one of the known transaction scripts has as many type
+// arguments as the script expects, and that they are valid. Other scripts are not checked.
+func ValidateScriptTypeArgs(script *aptostypes.Script) error {{
+	if script == nil {{
+		return fmt.Errorf("Unexpected nil script encountered when validating")
+	}}
+	if expected, ok := script_ty_arg_count_map[string(script.Code)]; ok && len(script.TyArgs) != expected {{
+		return fmt.Errorf("Was expecting %d type arguments, got %d", expected, len(script.TyArgs))
+	}}
+	for i, tyArg := range script.TyArgs {{
+		if err := aptostypes.ValidateTypeTag(tyArg); err != nil {{
+			return fmt.Errorf("Invalid type argument %d: %w", i, err)
+		}}
+	}}
+	return nil
+}}"#
+        )
+    }
+
+    fn output_script_function_decoder_map(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+// Identify a script function by its module, address included, and its name, since modules
+// of other accounts may reuse the names of the framework.
+type scriptFunctionKey struct {{
+	module   aptostypes.ModuleId
+	function aptostypes.Identifier
+}}
+
+var script_function_decoder_map = map[scriptFunctionKey]func(aptostypes.TransactionPayload) (ScriptFunctionCall, error) {{"#
+        )?;
+        self.out.indent();
+        for abi in abis {
+            writeln!(
+                self.out,
+                "{{{}, {}}}: decode_{}_{},",
+                Self::quote_module_id(abi.module_name()),
+                Self::quote_identifier(abi.name()),
+                abi.module_name().name(),
+                abi.name(),
+            )?;
+        }
+        self.out.unindent();
+        writeln!(self.out, "}}")
+    }
+
+    fn output_decoder_registry(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+// Turn the type arguments and the BCS-encoded arguments of a script function call into a
+// structured object.
+type DecoderFunc func(tyArgs []aptostypes.TypeTag, args [][]byte) (interface{{}}, error)
+
+// Map fully qualified function names such as "0x1::coin::transfer" to decoders.
+// The zero value is not usable: use `NewDecoderRegistry` instead.
+type DecoderRegistry struct {{
+	decoders map[string]DecoderFunc
+	observer DecodeObserver
+}}
+
+// Receive an event for every payload decoded by a `DecoderRegistry`, e.g. to count calls per
+// function. The methods must be safe for concurrent use if the registry is.
+type DecodeObserver interface {{
+	// Called after a successful decoding, with the function name qualified by its
+	// `ModuleId.String`, e.g. "0x1::coin::transfer".
+	OnDecoded(name string)
+	// Called with the error returned by `DecoderRegistry.Decode`.
+	OnError(err error)
+}}
+
+// Create a registry pre-populated with the decoders of the script functions of this package.
+func NewDecoderRegistry() *DecoderRegistry {{
+	registry := &DecoderRegistry{{decoders: make(map[string]DecoderFunc)}}"#
+        )?;
+        self.out.indent();
+        for abi in abis {
+            writeln!(
+                self.out,
+                "registry.Register(\"{0}::{1}::{2}\", builtinDecoder(decode_{1}_{2}))",
+                abi.module_name().address().to_hex_literal(),
+                abi.module_name().name(),
+                abi.name(),
+            )?;
         }
+        writeln!(self.out, "return registry")?;
         self.out.unindent();
-        writeln!(self.out, "}}")
+        writeln!(
+            self.out,
+            r#"}}
+
+// Register a decoder for the given fully qualified function name, replacing any previous one.
+// Panics if the name is not of the form "address::module::function".
+func (registry *DecoderRegistry) Register(name string, fn DecoderFunc) {{
+	key, err := canonicalFunctionName(name)
+	if err != nil {{
+		panic(err)
+	}}
+	registry.decoders[key] = fn
+}}
+
+// Notify `observer` of all the subsequent calls to `Decode`, or stop notifying if it is nil,
+// which is the default.
+func (registry *DecoderRegistry) SetObserver(observer DecodeObserver) {{
+	registry.observer = observer
+}}
+
+// Decode a script function payload with the registered decoder of the function. The raw
+// `*aptostypes.ScriptFunction` is returned for functions without a decoder.
+// A panic of the decoder is returned as an error.
+func (registry *DecoderRegistry) Decode(payload aptostypes.TransactionPayload) (call interface{{}}, err error) {{
+	var key string
+	if observer := registry.observer; observer != nil {{
+		defer func() {{
+			if err != nil {{
+				observer.OnError(err)
+			}} else {{
+				observer.OnDecoded(key)
+			}}
+		}}()
+	}}
+	defer recoverDecodingPanic(&err)
+	function, err := DecodeScriptFunction(payload)
+	if err != nil {{
+		return nil, err
+	}}
+	key = function.Module.String() + "::" + string(function.Function)
+	if fn := registry.decoders[key]; fn != nil {{
+		return fn(function.TyArgs, function.Args)
+	}}
+	return function, nil
+}}
+
+func builtinDecoder(helper func(aptostypes.TransactionPayload) (ScriptFunctionCall, error)) DecoderFunc {{
+	return func(tyArgs []aptostypes.TypeTag, args [][]byte) (interface{{}}, error) {{
+		return helper(&aptostypes.TransactionPayload__ScriptFunction{{
+			Value: aptostypes.ScriptFunction{{TyArgs: tyArgs, Args: args}},
+		}})
+	}}
+}}
+
+// A call decoded by a `SchemaDecoder`.
+type TypedScriptFunctionCall struct {{
+	TyArgs []aptostypes.TypeTag
+	Args   []interface{{}}
+}}
+
+// Create a decoder for a script function unknown to this package from the Move types of its
+// parameters, e.g. "address" and "u64" for a transfer:
+//
+//	registry.Register("0xcafe::pay::transfer", SchemaDecoder("address", "u64"))
+//
+// The decoded calls are `*TypedScriptFunctionCall`s, whose arguments are decoded by
+// `aptostypes.DecodeArguments`. Panics if a type cannot be parsed.
+func SchemaDecoder(types ...string) DecoderFunc {{
+	tags := make([]aptostypes.TypeTag, len(types))
+	for i, typ := range types {{
+		tag, err := aptostypes.ParseTypeTag(typ)
+		if err != nil {{
+			panic(err)
+		}}
+		tags[i] = tag
+	}}
+	return func(tyArgs []aptostypes.TypeTag, args [][]byte) (interface{{}}, error) {{
+		values, err := aptostypes.DecodeArguments(args, tags)
+		if err != nil {{
+			return nil, err
+		}}
+		return &TypedScriptFunctionCall{{TyArgs: tyArgs, Args: values}}, nil
+	}}
+}}
+
+func canonicalFunctionName(name string) (string, error) {{
+	parts := strings.Split(name, "::")
+	if len(parts) != 3 {{
+		return "", fmt.Errorf("Invalid function name: %s", name)
+	}}
+	address, err := aptostypes.ParseAccountAddress(parts[0])
+	if err != nil {{
+		return "", err
+	}}
+	return aptostypes.ModuleId{{Address: address, Name: aptostypes.Identifier(parts[1])}}.String() + "::" + parts[2], nil
+}}"#
+        )
     }
 
-    fn output_script_function_decoder_map(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+    fn output_round_trip_tests(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+        let aptos_types_package = match &self.aptos_module_path {
+            Some(path) => format!("{}/aptostypes", path),
+            None => "aptostypes".into(),
+        };
+        let mut imports = vec![
+            "\"bytes\"".to_string(),
+            "\"errors\"".to_string(),
+            "\"reflect\"".to_string(),
+            "\"testing\"".to_string(),
+            String::new(),
+            format!("\"{}\"", aptos_types_package),
+        ];
+        if abis.iter().any(|abi| {
+            abi.args()
+                .iter()
+                .any(|arg| Self::quote_type(arg.type_tag()).ends_with("serde.Uint128"))
+        }) {
+            imports.push(format!(
+                "\"{}/serde\"",
+                self.serde_module_path.as_deref().unwrap_or(
+                    "github.com/aptos-labs/serde-reflection/serde-generate/runtime/golang"
+                )
+            ));
+        }
+        writeln!(
+            self.out,
+            "package {}\n\nimport (\n{}\n)",
+            self.package_name,
+            imports
+                .iter()
+                .map(|import| format!("\t{}", import))
+                .collect::<Vec<_>>()
+                .join("\n"),
+        )?;
         writeln!(
             self.out,
             r#"
-var script_function_decoder_map = map[string]func(aptostypes.TransactionPayload) (ScriptFunctionCall, error) {{"#
+// A call of every encoder with distinct placeholder arguments, so that decoding them in the
+// wrong order fails the tests, and the expected `String` of the call.
+var scriptFunctionTests = []struct {{
+	name    string
+	tyArgs  []aptostypes.TypeTag
+	args    []interface{{}}
+	payload aptostypes.TransactionPayload
+	text    string
+}}{{"#
         )?;
         self.out.indent();
         for abi in abis {
+            let ty_args = (1..=abi.ty_args().len())
+                .map(|i| {
+                    format!(
+                        "aptostypes.StructTypeTag(aptostypes.CoreCodeAddress, \"placeholder\", \"T{}\")",
+                        i
+                    )
+                })
+                .collect::<Vec<_>>();
+            let args = abi
+                .args()
+                .iter()
+                .enumerate()
+                .map(|(i, arg)| Self::quote_placeholder(arg.type_tag(), i + 1))
+                .collect::<Vec<_>>();
+            let mut text = format!(
+                "{}::{}::{}",
+                abi.module_name().address().to_hex_literal(),
+                abi.module_name().name(),
+                abi.name()
+            );
+            if !abi.ty_args().is_empty() {
+                let ty_args = (1..=abi.ty_args().len())
+                    .map(|i| format!("0x1::placeholder::T{}", i))
+                    .collect::<Vec<_>>();
+                text.push_str(&format!("<{}>", ty_args.join(", ")));
+            }
+            for (i, arg) in abi.args().iter().enumerate() {
+                text.push_str(&format!(
+                    "\n  {}: {}",
+                    arg.name(),
+                    Self::quote_placeholder_text(arg.type_tag(), i + 1)
+                ));
+            }
             writeln!(
                 self.out,
-                "\"{0}_{1}\": decode_{0}_{1},",
+                r#"{{
+	name:    "{}_{}",
+	tyArgs:  []aptostypes.TypeTag{{{}}},
+	args:    []interface{{}}{{{}}},
+	payload: Encode{}({}),
+	text:    {:?},
+}},"#,
                 abi.module_name().name(),
                 abi.name(),
+                ty_args.join(", "),
+                args.join(", "),
+                Self::script_function_variant_name(abi),
+                [ty_args.clone(), args.clone()].concat().join(", "),
+                text,
             )?;
         }
         self.out.unindent();
-        writeln!(self.out, "}}")
+        writeln!(
+            self.out,
+            r#"}}
+
+func TestScriptFunctionRoundTrip(t *testing.T) {{
+	for _, test := range scriptFunctionTests {{
+		call, err := DecodeScriptFunctionPayload(test.payload)
+		if err != nil {{
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}}
+		if call.Name() != test.name {{
+			t.Errorf("%s: decoded as %s", test.name, call.Name())
+		}}
+		function := test.payload.(*aptostypes.TransactionPayload__ScriptFunction).Value
+		if call.ModuleId() != function.Module || call.FunctionName() != function.Function {{
+			t.Errorf("%s: decoded as %v::%s", test.name, call.ModuleId(), call.FunctionName())
+		}}
+		tyArgs := call.TyArgs()
+		if len(tyArgs) != len(test.tyArgs) {{
+			t.Errorf("%s: expected %d type arguments, got %d", test.name, len(test.tyArgs), len(tyArgs))
+			continue
+		}}
+		for i := range tyArgs {{
+			if !aptostypes.EqualTypeTag(tyArgs[i], test.tyArgs[i]) {{
+				t.Errorf("%s: type argument %d: expected %v, got %v", test.name, i, test.tyArgs[i], tyArgs[i])
+			}}
+		}}
+		args := call.ArgValues()
+		if len(args) != len(test.args) {{
+			t.Errorf("%s: expected %d arguments, got %d", test.name, len(test.args), len(args))
+			continue
+		}}
+		for i := range args {{
+			if !reflect.DeepEqual(args[i], test.args[i]) {{
+				t.Errorf("%s: argument %d: expected %v, got %v", test.name, i, test.args[i], args[i])
+			}}
+		}}
+		expected, err := test.payload.BcsSerialize()
+		if err != nil {{
+			t.Fatal(err)
+		}}
+		if encoded, err := call.Encode().BcsSerialize(); err != nil || !bytes.Equal(encoded, expected) {{
+			t.Errorf("%s: re-encoding gave a different payload", test.name)
+		}}
+		// An argument followed by a trailing byte must be rejected.
+		for i := range function.Args {{
+			tampered := function
+			tampered.Args = append([][]byte(nil), function.Args...)
+			tampered.Args[i] = append(append([]byte(nil), function.Args[i]...), 0xff)
+			if _, err := DecodeScriptFunctionPayload(&aptostypes.TransactionPayload__ScriptFunction{{Value: tampered}}); err == nil {{
+				t.Errorf("%s: argument %d: a trailing byte was accepted", test.name, i)
+			}}
+		}}
+	}}
+}}
+
+func TestScriptFunctionClone(t *testing.T) {{
+	for _, test := range scriptFunctionTests {{
+		call, err := DecodeScriptFunctionPayload(test.payload)
+		if err != nil {{
+			t.Fatalf("%s: %v", test.name, err)
+		}}
+		clone := call.Clone()
+		if !clone.Equal(call) {{
+			t.Errorf("%s: the clone differs from the call", test.name)
+			continue
+		}}
+		// Changing the clone in place must leave the call as it was.
+		for _, arg := range clone.ArgValues() {{
+			switch arg := arg.(type) {{
+			case []byte:
+				arg[0]++
+			case [][]byte:
+				arg[0][0]++
+			case []aptostypes.AccountAddress:
+				arg[0][0]++
+			case []uint64:
+				arg[0]++
+			}}
+		}}
+		for _, tag := range clone.TyArgs() {{
+			tag.(*aptostypes.TypeTag__Struct).Value.Name = "Changed"
+		}}
+		expected, err := test.payload.BcsSerialize()
+		if err != nil {{
+			t.Fatal(err)
+		}}
+		if encoded, err := call.Encode().BcsSerialize(); err != nil || !bytes.Equal(encoded, expected) {{
+			t.Errorf("%s: the clone shares memory with the call", test.name)
+		}}
+	}}
+}}
+
+func TestScriptFunctionString(t *testing.T) {{
+	for _, test := range scriptFunctionTests {{
+		call, err := DecodeScriptFunctionPayload(test.payload)
+		if err != nil {{
+			t.Fatalf("%s: %v", test.name, err)
+		}}
+		if text := call.String(); text != test.text {{
+			t.Errorf("%s: expected %q, got %q", test.name, test.text, text)
+		}}
+	}}
+}}
+
+func TestDecoderRegistry(t *testing.T) {{
+	registry := NewDecoderRegistry()
+	for _, test := range scriptFunctionTests {{
+		decoded, err := registry.Decode(test.payload)
+		call, ok := decoded.(ScriptFunctionCall)
+		if err != nil || !ok {{
+			t.Errorf("%s: decoded as %T: %v", test.name, decoded, err)
+			continue
+		}}
+		if expected, err := DecodeScriptFunctionPayload(test.payload); err != nil || !call.Equal(expected) {{
+			t.Errorf("%s: the registry gave a different call: %v", test.name, err)
+		}}
+	}}
+
+	// Functions without a decoder are returned as is, until one is registered.
+	unknown := &aptostypes.TransactionPayload__ScriptFunction{{Value: aptostypes.ScriptFunction{{
+		Module:   aptostypes.ModuleId{{Address: aptostypes.AccountAddress{{31: 0xab}}, Name: "m"}},
+		Function: "f",
+	}}}}
+	if decoded, err := registry.Decode(unknown); err != nil || !reflect.DeepEqual(decoded, &unknown.Value) {{
+		t.Fatalf("unexpected decoding of an unknown function: %v, %v", decoded, err)
+	}}
+	registry.Register("0x00ab::m::f", func(tyArgs []aptostypes.TypeTag, args [][]byte) (interface{{}}, error) {{
+		return "custom", nil
+	}})
+	observer := &namesObserver{{}}
+	registry.SetObserver(observer)
+	if decoded, err := registry.Decode(unknown); err != nil || decoded != "custom" {{
+		t.Fatalf("the registered decoder was not used: %v, %v", decoded, err)
+	}}
+	// The observer sees the short form printed by `ModuleId.String`.
+	if !reflect.DeepEqual(observer.names, []string{{"0xab::m::f"}}) {{
+		t.Fatalf("unexpected observed names %v", observer.names)
+	}}
+}}
+
+type namesObserver struct {{
+	names []string
+}}
+
+func (observer *namesObserver) OnDecoded(name string) {{
+	observer.names = append(observer.names, name)
+}}
+
+func (observer *namesObserver) OnError(err error) {{}}
+
+func TestBcsSerializeScriptFunctionBatch(t *testing.T) {{
+	var calls []ScriptFunctionCall
+	for _, test := range scriptFunctionTests {{
+		call, err := DecodeScriptFunctionPayload(test.payload)
+		if err != nil {{
+			t.Fatalf("%s: %v", test.name, err)
+		}}
+		calls = append(calls, call)
+	}}
+	payloads, err := BcsSerializeScriptFunctionBatch(make([]byte, 0, 16), calls)
+	if err != nil || len(payloads) != len(calls) {{
+		t.Fatalf("serialized %d of %d calls: %v", len(payloads), len(calls), err)
+	}}
+	for i, test := range scriptFunctionTests {{
+		expected, err := test.payload.BcsSerialize()
+		if err != nil {{
+			t.Fatal(err)
+		}}
+		if !bytes.Equal(payloads[i], expected) {{
+			t.Errorf("%s: the batch gave a different payload", test.name)
+		}}
+		// The payloads share a buffer, so appending to one must not overwrite the next.
+		if cap(payloads[i]) != len(payloads[i]) {{
+			t.Errorf("%s: the payload has spare capacity", test.name)
+		}}
+	}}
+}}
+
+func TestDecodingErrors(t *testing.T) {{
+	if _, err := DecodeScriptFunctionPayload(&aptostypes.TransactionPayload__Script{{}}); !errors.Is(err, ErrUnknownVariant) {{
+		t.Fatalf("expected ErrUnknownVariant for a script, got %v", err)
+	}}
+	unknown := &aptostypes.TransactionPayload__ScriptFunction{{Value: aptostypes.ScriptFunction{{
+		Module:   aptostypes.ModuleId{{Address: aptostypes.AccountAddress{{31: 0xab}}, Name: "m"}},
+		Function: "f",
+	}}}}
+	if _, err := DecodeScriptFunctionPayload(unknown); !errors.Is(err, ErrUnknownScript) {{
+		t.Fatalf("expected ErrUnknownScript for an unknown function, got %v", err)
+	}}
+	// A module of another account with the name of a module of this package.
+	for _, test := range scriptFunctionTests {{
+		function := test.payload.(*aptostypes.TransactionPayload__ScriptFunction).Value
+		function.Module.Address[0] ^= 0xca
+		if _, err := DecodeScriptFunctionPayload(&aptostypes.TransactionPayload__ScriptFunction{{Value: function}}); !errors.Is(err, ErrUnknownScript) {{
+			t.Errorf("%s: expected ErrUnknownScript at %v, got %v", test.name, function.Module, err)
+		}}
+	}}
+}}
+
+func TestDecodeMalformedPayloads(t *testing.T) {{
+	decode := func(function aptostypes.ScriptFunction) error {{
+		_, err := DecodeScriptFunctionPayload(&aptostypes.TransactionPayload__ScriptFunction{{Value: function}})
+		return err
+	}}
+	for _, test := range scriptFunctionTests {{
+		function := test.payload.(*aptostypes.TransactionPayload__ScriptFunction).Value
+		for i := range function.Args {{
+			tampered := function
+			tampered.Args = append([][]byte(nil), function.Args...)
+			tampered.Args[i] = nil
+			if err := decode(tampered); err == nil {{
+				t.Errorf("%s: argument %d: an empty argument was accepted", test.name, i)
+			}}
+		}}
+		if len(function.Args) > 0 {{
+			tampered := function
+			tampered.Args = function.Args[:len(function.Args)-1]
+			if err := decode(tampered); err == nil {{
+				t.Errorf("%s: a missing argument was accepted", test.name)
+			}}
+		}}
+		if len(function.TyArgs) > 0 {{
+			tampered := function
+			tampered.TyArgs = nil
+			if err := decode(tampered); err == nil {{
+				t.Errorf("%s: missing type arguments were accepted", test.name)
+			}}
+		}}
+	}}
+	for _, payload := range []aptostypes.TransactionPayload{{nil, (*aptostypes.TransactionPayload__ScriptFunction)(nil)}} {{
+		if _, err := DecodeScriptFunctionPayload(payload); err == nil {{
+			t.Errorf("%#v: the payload was accepted", payload)
+		}}
+	}}
+
+	// A panic of a registered decoder is returned as an error.
+	registry := NewDecoderRegistry()
+	registry.Register("0xab::m::f", func(tyArgs []aptostypes.TypeTag, args [][]byte) (interface{{}}, error) {{
+		return args[0], nil
+	}})
+	unknown := &aptostypes.TransactionPayload__ScriptFunction{{Value: aptostypes.ScriptFunction{{
+		Module:   aptostypes.ModuleId{{Address: aptostypes.AccountAddress{{31: 0xab}}, Name: "m"}},
+		Function: "f",
+	}}}}
+	if decoded, err := registry.Decode(unknown); decoded != nil || err == nil {{
+		t.Fatalf("the panic of the decoder gave %v, %v", decoded, err)
+	}}
+}}
+
+func TestDecoderCache(t *testing.T) {{
+	cache := NewDecoderCache(1)
+	for _, test := range scriptFunctionTests {{
+		payload, err := test.payload.BcsSerialize()
+		if err != nil {{
+			t.Fatal(err)
+		}}
+		for i := 0; i < 2; i++ {{
+			call, err := cache.Decode(payload)
+			if err != nil {{
+				t.Fatalf("%s: %v", test.name, err)
+			}}
+			if encoded, err := call.Encode().BcsSerialize(); err != nil || !bytes.Equal(encoded, payload) {{
+				t.Fatalf("%s: the cached call gave a different payload", test.name)
+			}}
+		}}
+		if cache.Len() != 1 {{
+			t.Fatalf("%s: the cache holds %d calls, expected 1", test.name, cache.Len())
+		}}
+	}}
+	if _, err := cache.Decode([]byte{{0xff}}); err == nil || cache.Len() > 1 {{
+		t.Fatalf("an undecodable payload gave %v and %d cached calls", err, cache.Len())
+	}}
+}}
+
+// Compare a cache hit with a full decode of the same payload:
+//
+//	go test -run NONE -bench DecoderCache
+func BenchmarkDecoderCache(b *testing.B) {{
+	if len(scriptFunctionTests) == 0 {{
+		b.Skip("no script functions")
+	}}
+	payload, err := scriptFunctionTests[0].payload.BcsSerialize()
+	if err != nil {{
+		b.Fatal(err)
+	}}
+	b.Run("cached", func(b *testing.B) {{
+		cache := NewDecoderCache(16)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {{
+			if _, err := cache.Decode(payload); err != nil {{
+				b.Fatal(err)
+			}}
+		}}
+	}})
+	b.Run("uncached", func(b *testing.B) {{
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {{
+			decoded, err := aptostypes.BcsDeserializeTransactionPayload(payload)
+			if err == nil {{
+				_, err = DecodeScriptFunctionPayload(decoded)
+			}}
+			if err != nil {{
+				b.Fatal(err)
+			}}
+		}}
+	}})
+}}
+
+// Compare serializing a batch of calls into a shared buffer with serializing each one:
+//
+//	go test -run NONE -bench ScriptFunctionBatch
+func BenchmarkBcsSerializeScriptFunctionBatch(b *testing.B) {{
+	if len(scriptFunctionTests) == 0 {{
+		b.Skip("no script functions")
+	}}
+	call, err := DecodeScriptFunctionPayload(scriptFunctionTests[0].payload)
+	if err != nil {{
+		b.Fatal(err)
+	}}
+	calls := make([]ScriptFunctionCall, 1000)
+	for i := range calls {{
+		calls[i] = call
+	}}
+	b.Run("batch", func(b *testing.B) {{
+		buf := make([]byte, 0, 1<<20)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {{
+			if _, err := BcsSerializeScriptFunctionBatch(buf, calls); err != nil {{
+				b.Fatal(err)
+			}}
+		}}
+	}})
+	b.Run("single", func(b *testing.B) {{
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {{
+			for _, call := range calls {{
+				if _, err := call.Encode().BcsSerialize(); err != nil {{
+					b.Fatal(err)
+				}}
+			}}
+		}}
+	}})
+}}"#
+        )
+    }
+
+    fn output_decoder_cache(&mut self) -> Result<()> {
+        writeln!(
+            self.out,
+            r#"
+// A bounded cache of decoded script function calls, keyed by the SHA-256 hash of the BCS
+// encoding of the payload, which evicts the least recently used entries first. It is safe
+// for concurrent use. The zero value is not usable: use `NewDecoderCache` instead.
+type DecoderCache struct {{
+	mutex   sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+}}
+
+type decoderCacheEntry struct {{
+	key  [sha256.Size]byte
+	call ScriptFunctionCall
+}}
+
+// Create a cache holding at most `size` decoded calls. Panics if `size` is not positive.
+func NewDecoderCache(size int) *DecoderCache {{
+	if size <= 0 {{
+		panic(fmt.Sprintf("Invalid decoder cache size: %d", size))
+	}}
+	return &DecoderCache{{
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*list.Element, size),
+		order:   list.New(),
+	}}
+}}
+
+// Decode the BCS encoding of a `TransactionPayload` with `DecodeScriptFunctionPayload`,
+// reusing the result of a previous call with the same bytes. The returned call is a clone
+// that the caller may modify freely. Errors are not cached.
+func (cache *DecoderCache) Decode(payload []byte) (ScriptFunctionCall, error) {{
+	key := sha256.Sum256(payload)
+	cache.mutex.Lock()
+	if element, ok := cache.entries[key]; ok {{
+		cache.order.MoveToFront(element)
+		call := element.Value.(*decoderCacheEntry).call
+		cache.mutex.Unlock()
+		return call.Clone(), nil
+	}}
+	cache.mutex.Unlock()
+
+	decoded, err := aptostypes.BcsDeserializeTransactionPayload(payload)
+	if err != nil {{
+		return nil, err
+	}}
+	call, err := DecodeScriptFunctionPayload(decoded)
+	if err != nil {{
+		return nil, err
+	}}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if _, ok := cache.entries[key]; !ok {{
+		cache.entries[key] = cache.order.PushFront(&decoderCacheEntry{{key: key, call: call.Clone()}})
+		if cache.order.Len() > cache.size {{
+			oldest := cache.order.Remove(cache.order.Back()).(*decoderCacheEntry)
+			delete(cache.entries, oldest.key)
+		}}
+	}}
+	return call, nil
+}}
+
+// Number of calls currently in the cache.
+func (cache *DecoderCache) Len() int {{
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.order.Len()
+}}"#
+        )
     }
 
     fn output_encoding_helpers(&mut self, abis: &[ScriptABI]) -> Result<()> {
@@ -557,6 +2370,14 @@ var script_function_decoder_map = map[string]func(aptostypes.TransactionPayload)
 
     fn output_encoding_helper(&mut self, type_tag: &TypeTag) -> Result<()> {
         let encoding = match Self::bcs_primitive_type_name(type_tag) {
+            None if Self::vector_helper_name(type_tag).is_some() => format!(
+                r#"
+    if val, err := aptostypes.BcsSerialize{}(arg); err == nil {{
+        return val;
+    }}
+    "#,
+                Self::vector_helper_name(type_tag).unwrap()
+            ),
             None => r#"
     if val, err := arg.BcsSerialize(); err == nil {{
         return val;
@@ -617,7 +2438,7 @@ func encode_{}_argument(arg {}) []byte {{
             self.out,
             r#"
 func decode_{0}_argument(arg aptostypes.TransactionArgument) (value {1}, err error) {{
-	if arg, ok := arg.(*aptostypes.TransactionArgument__{2}); ok {{
+	if arg, ok := arg.(*aptostypes.TransactionArgument__{2}); ok && arg != nil {{
 		{3}
 	}} else {{
 		err = fmt.Errorf("Was expecting a {2} argument")
@@ -632,6 +2453,39 @@ func decode_{0}_argument(arg aptostypes.TransactionArgument) (value {1}, err err
         )
     }
 
+    // Decode the BCS arguments of script functions whose types have a `Deserialize*` method
+    // in `aptostypes.Deserializer`, rejecting trailing bytes like `aptostypes.BcsDeserialize*`.
+    fn output_bcs_decoding_helpers(&mut self, abis: &[ScriptFunctionABI]) -> Result<()> {
+        let required_types: BTreeMap<_, _> = abis
+            .iter()
+            .flat_map(|abi| abi.args())
+            .filter_map(|arg| {
+                Self::bcs_primitive_type_name(arg.type_tag()).map(|type_name| {
+                    (
+                        common::mangle_type(arg.type_tag()),
+                        (type_name, Self::quote_type(arg.type_tag())),
+                    )
+                })
+            })
+            .collect();
+        for (mangled_name, (type_name, quoted_type)) in required_types {
+            writeln!(
+                self.out,
+                r#"
+func decode_{0}_bcs_argument(arg []byte) (value {1}, err error) {{
+	deserializer := aptostypes.NewDeserializer(arg, aptostypes.DefaultDeserializerOptions)
+	if value, err = deserializer.Deserialize{2}(); err == nil && deserializer.Remaining() > 0 {{
+		err = fmt.Errorf("Some input bytes were not read")
+	}}
+	return
+}}
+"#,
+                mangled_name, quoted_type, type_name,
+            )?;
+        }
+        Ok(())
+    }
+
     fn output_code_constant(&mut self, abi: &ScriptABI) -> Result<()> {
         if let ScriptABI::TransactionScript(abi) = abi {
             writeln!(
@@ -648,6 +2502,33 @@ func decode_{0}_argument(arg aptostypes.TransactionArgument) (value {1}, err err
         Ok(())
     }
 
+    /// The name of the variant of `ScriptCall` or `ScriptFunctionCall` for `abi`, which is
+    /// also the suffix of its encoder.
+    fn variant_name(abi: &ScriptABI) -> String {
+        match abi {
+            ScriptABI::TransactionScript(abi) => abi.name().to_camel_case(),
+            ScriptABI::ScriptFunction(abi) => Self::script_function_variant_name(abi),
+        }
+    }
+
+    /// Script function variants are named after the module and the function, since function
+    /// names alone are not unique.
+    fn script_function_variant_name(abi: &ScriptFunctionABI) -> String {
+        format!(
+            "{}{}",
+            abi.module_name().name().to_string().to_camel_case(),
+            abi.name().to_camel_case()
+        )
+    }
+
+    /// The call interface implemented by the variant for `abi`.
+    fn interface_name(abi: &ScriptABI) -> &'static str {
+        match abi {
+            ScriptABI::TransactionScript(_) => "ScriptCall",
+            ScriptABI::ScriptFunction(_) => "ScriptFunctionCall",
+        }
+    }
+
     fn quote_identifier(ident: &str) -> String {
         format!("\"{}\"", ident)
     }
@@ -724,7 +2605,7 @@ func decode_{0}_argument(arg aptostypes.TransactionArgument) (value {1}, err err
                 Bool => "[]bool".into(),
                 U8 => "[]byte".into(),
                 U64 => "[]uint64".into(),
-                U128 => "[]uint128".into(),
+                U128 => "[]serde.Uint128".into(),
                 Address => "[]aptostypes.AccountAddress".into(),
                 Vector(type_tag) if type_tag.as_ref() == &U8 => "[][]byte".into(),
                 _ => common::type_not_allowed(type_tag),
@@ -733,6 +2614,102 @@ func decode_{0}_argument(arg aptostypes.TransactionArgument) (value {1}, err err
         }
     }
 
+    // A Go value of the type of `type_tag` made from `n`, for tests.
+    fn quote_placeholder(type_tag: &TypeTag, n: usize) -> String {
+        use TypeTag::*;
+        match type_tag {
+            Bool => (n % 2 == 1).to_string(),
+            U8 => format!("uint8({})", n),
+            U64 => format!("uint64({})", n),
+            U128 => format!("serde.Uint128{{Low: {}}}", n),
+            Address => format!("aptostypes.AccountAddress{{31: {}}}", n),
+            Vector(type_tag) => match type_tag.as_ref() {
+                Bool => format!("[]bool{{{}}}", n % 2 == 1),
+                U8 => format!("[]byte{{{}}}", n),
+                U64 => format!("[]uint64{{{}}}", n),
+                U128 => format!("[]serde.Uint128{{{{Low: {}}}}}", n),
+                Address => format!("[]aptostypes.AccountAddress{{{{31: {}}}}}", n),
+                Vector(type_tag) if type_tag.as_ref() == &U8 => format!("[][]byte{{{{{}}}}}", n),
+                _ => common::type_not_allowed(type_tag),
+            },
+            Struct(_) | Signer => common::type_not_allowed(type_tag),
+        }
+    }
+
+    /// How `String` methods print the value of `quote_placeholder`.
+    fn quote_placeholder_text(type_tag: &TypeTag, n: usize) -> String {
+        use TypeTag::*;
+        let address = format!("0x{:064x}", n);
+        match type_tag {
+            Bool => (n % 2 == 1).to_string(),
+            U8 | U64 | U128 => n.to_string(),
+            Address => address,
+            Vector(type_tag) => match type_tag.as_ref() {
+                Bool => format!("[{}]", n % 2 == 1),
+                U8 => format!("0x{:02x}", n),
+                U64 | U128 => format!("[{}]", n),
+                Address => format!("[{}]", address),
+                Vector(type_tag) if type_tag.as_ref() == &U8 => format!("[0x{:02x}]", n),
+                _ => common::type_not_allowed(type_tag),
+            },
+            Struct(_) | Signer => common::type_not_allowed(type_tag),
+        }
+    }
+
+    fn quote_move_type(type_tag: &TypeTag) -> String {
+        use TypeTag::*;
+        match type_tag {
+            Bool => "bool".into(),
+            U8 => "u8".into(),
+            U64 => "u64".into(),
+            U128 => "u128".into(),
+            Address => "address".into(),
+            Vector(type_tag) => format!("vector<{}>", Self::quote_move_type(type_tag)),
+            Struct(_) | Signer => common::type_not_allowed(type_tag),
+        }
+    }
+
+    // Vectors other than `vector<u8>` are plain slices in Go, which are encoded by the
+    // helpers `BcsSerialize<name>` and `BcsDeserialize<name>` of `aptostypes`.
+    fn vector_helper_name(type_tag: &TypeTag) -> Option<&'static str> {
+        use TypeTag::*;
+        match type_tag {
+            Vector(type_tag) => match type_tag.as_ref() {
+                Bool => Some("BoolVector"),
+                U64 => Some("U64Vector"),
+                U128 => Some("U128Vector"),
+                Address => Some("AddressVector"),
+                Vector(type_tag) if type_tag.as_ref() == &U8 => Some("BytesVector"),
+                _ => None,
+            },
+            _ => None,
+        }
+    }
+
+    fn is_bytes_vector(type_tag: &TypeTag) -> bool {
+        Self::vector_helper_name(type_tag) == Some("BytesVector")
+    }
+
+    // How `String` methods print a value of the given type.
+    fn quote_value_format(type_tag: &TypeTag, value: &str) -> (&'static str, String) {
+        use TypeTag::*;
+        match type_tag {
+            Bool => ("%t", value.into()),
+            U8 | U64 => ("%d", value.into()),
+            U128 => ("%s", format!("aptostypes.U128({})", value)),
+            Address => ("%s", format!("{}.ToHex()", value)),
+            Vector(item) => match item.as_ref() {
+                U8 => ("0x%x", value.into()),
+                Bool | U64 => ("%v", value.into()),
+                _ => match Self::vector_helper_name(type_tag) {
+                    Some(name) => ("%s", format!("aptostypes.Format{}({})", name, value)),
+                    None => common::type_not_allowed(type_tag),
+                },
+            },
+            Struct(_) | Signer => common::type_not_allowed(type_tag),
+        }
+    }
+
     fn quote_transaction_argument(type_tag: &TypeTag, name: &str) -> String {
         format!(
             "encode_{}_argument({})",
@@ -783,6 +2760,8 @@ pub struct Installer {
     install_dir: PathBuf,
     serde_module_path: Option<String>,
     aptos_module_path: Option<String>,
+    enum_style: EnumStyle,
+    min_go_version: u32,
 }
 
 impl Installer {
@@ -795,7 +2774,122 @@ impl Installer {
             install_dir,
             serde_module_path,
             aptos_module_path,
+            enum_style: EnumStyle::default(),
+            min_go_version: GENERICS_GO_VERSION,
+        }
+    }
+
+    /// Select how the variants of the generated call enums are exposed.
+    pub fn with_enum_style(mut self, enum_style: EnumStyle) -> Self {
+        self.enum_style = enum_style;
+        self
+    }
+
+    /// Target Go 1.`minor`, 1.18 by default, in `go.mod` and in build constraints. Below
+    /// 1.18, the files using generics, e.g. the visitors of `EnumStyle::Visitor`, are only
+    /// compiled by Go 1.18 and later. Panics if `minor` is below `OLDEST_GO_VERSION`.
+    pub fn with_min_go_version(mut self, minor: u32) -> Self {
+        assert!(
+            minor >= OLDEST_GO_VERSION,
+            "the generated Go code requires Go 1.{} or later",
+            OLDEST_GO_VERSION
+        );
+        self.min_go_version = minor;
+        self
+    }
+
+    /// Go 1.16 and earlier only understand the `// +build` form of build constraints.
+    fn add_legacy_build_constraints(&self, code: &str) -> String {
+        if self.min_go_version >= 17 {
+            return code.to_string();
+        }
+        code.replace(
+            "//go:build go1.18\n",
+            "//go:build go1.18\n// +build go1.18\n",
+        )
+    }
+}
+
+impl Installer {
+    /// Install the hand-written helpers of the `aptostypes` package next to the definitions
+    /// generated by serde-generate, and format the latter.
+    pub fn install_aptos_types_runtime(
+        &self,
+    ) -> std::result::Result<(), Box<dyn std::error::Error>> {
+        let dir_path = self.install_dir.join("aptostypes");
+        std::fs::create_dir_all(&dir_path)?;
+        // The definitions generated by serde-generate are not formatted.
+        let lib_path = dir_path.join("lib.go");
+        if lib_path.exists() {
+            let code = String::from_utf8(std::fs::read(&lib_path)?)?;
+            let code = Self::wrap_unknown_variant_errors(&code);
+            let code = Self::use_runtime_deserializer(&code);
+            std::fs::write(&lib_path, gofmt(code.into_bytes())?)?;
+        }
+        for (name, content) in APTOS_TYPES_RUNTIME {
+            let content = match &self.serde_module_path {
+                Some(path) => content.replace(DEFAULT_SERDE_MODULE_PATH, path),
+                None => content.to_string(),
+            };
+            std::fs::write(
+                dir_path.join(name),
+                self.add_legacy_build_constraints(&content),
+            )?;
         }
+        Ok(())
+    }
+}
+
+impl Installer {
+    /// The enum decoders generated by serde-generate report unknown variant indices with
+    /// `fmt.Errorf("Unknown variant index for <Enum>: %d", index)`. Wrap `ErrUnknownVariant`
+    /// instead, so that callers can test for it with `errors.Is`.
+    fn wrap_unknown_variant_errors(code: &str) -> String {
+        let prefix = "fmt.Errorf(\"Unknown variant index for ";
+        let suffix = ": %d\", index)";
+        code.lines()
+            .map(|line| {
+                if line.contains(prefix) && line.ends_with(suffix) {
+                    line.replace(prefix, "fmt.Errorf(\"%w index for ")
+                        .replace(suffix, ": %d\", ErrUnknownVariant, index)")
+                } else {
+                    line.to_string()
+                }
+            })
+            .map(|line| line + "\n")
+            .collect()
+    }
+}
+
+impl Installer {
+    /// The `BcsDeserialize*` functions generated by serde-generate decode with
+    /// `bcs.NewDeserializer`, which neither bounds sequence lengths by the input left nor
+    /// reports offsets. Decode with the `Deserializer` of the runtime instead, configured by
+    /// `DefaultDeserializerOptions`.
+    fn use_runtime_deserializer(code: &str) -> String {
+        code.replace(
+            "bcs.NewDeserializer(input)",
+            "NewDeserializer(input, DefaultDeserializerOptions)",
+        )
+    }
+}
+
+impl Installer {
+    /// Declare a Go module rooted at the installation directory, so that the generated
+    /// packages can be imported as `<module_path>/aptostypes` and `<module_path>/<name>`.
+    /// Versions of the dependencies are left to `go mod tidy`.
+    pub fn install_go_mod(
+        &self,
+        module_path: &str,
+    ) -> std::result::Result<(), Box<dyn std::error::Error>> {
+        std::fs::create_dir_all(&self.install_dir)?;
+        let mut file = std::fs::File::create(self.install_dir.join("go.mod"))?;
+        writeln!(
+            file,
+            "module {}\n\ngo 1.{}",
+            module_path, self.min_go_version
+        )?;
+        Ok(())
     }
 }
 
@@ -809,8 +2903,30 @@ impl crate::SourceInstaller for Installer {
     ) -> std::result::Result<(), Self::Error> {
         let dir_path = self.install_dir.join(name);
         std::fs::create_dir_all(&dir_path)?;
+        // Keep the generic visitors out of `lib.go` if the targeted Go version has no generics.
+        let separate_visitors =
+            self.enum_style == EnumStyle::Visitor && self.min_go_version < GENERICS_GO_VERSION;
         let mut file = std::fs::File::create(dir_path.join("lib.go"))?;
-        output(
+        output_with_enum_style(
+            &mut file,
+            self.serde_module_path.clone(),
+            self.aptos_module_path.clone(),
+            name.to_string(),
+            abis,
+            if separate_visitors {
+                EnumStyle::Interface
+            } else {
+                self.enum_style
+            },
+        )?;
+        if separate_visitors {
+            let mut code = Vec::new();
+            output_visitors_file(&mut code, name.to_string(), abis)?;
+            let code = self.add_legacy_build_constraints(&String::from_utf8(code)?);
+            std::fs::write(dir_path.join("visitors.go"), code)?;
+        }
+        let mut file = std::fs::File::create(dir_path.join("lib_test.go"))?;
+        output_round_trip_tests(
             &mut file,
             self.serde_module_path.clone(),
             self.aptos_module_path.clone(),
@@ -20,6 +20,14 @@ enum Language {
 }
 }
 
+arg_enum! {
+#[derive(Debug, StructOpt)]
+enum GoEnumStyle {
+    Interface,
+    Visitor,
+}
+}
+
 #[derive(Debug, StructOpt)]
 #[structopt(name = "Aptos SDK Builder", about = "Generate boilerplate Aptos SDKs")]
 struct Options {
@@ -58,10 +66,48 @@ struct Options {
     /// Optional package name (Python) or module path (Go) of the `aptos_types` dependency.
     #[structopt(long)]
     package_name: Option<String>,
+
+    /// Go module path of the generated packages, e.g. "github.com/myorg/aptos-bindings".
+    /// A `go.mod` declaring this module is written in `target_source_dir`, and the generated
+    /// packages import `aptostypes` from this module unless `--package-name` is given.
+    #[structopt(long)]
+    module_path: Option<String>,
+
+    /// How Go exposes the variants of `ScriptCall` and `ScriptFunctionCall`. "visitor" also
+    /// generates generic visitor interfaces, which require Go 1.18.
+    #[structopt(long, possible_values = &GoEnumStyle::variants(), case_insensitive = true, default_value = "Interface")]
+    go_enum_style: GoEnumStyle,
+
+    /// Oldest Go version that the generated Go packages must compile with, e.g. "1.16".
+    /// Below 1.18, the files using generics are only compiled by Go 1.18 and later.
+    #[structopt(long, default_value = "1.18", parse(try_from_str = parse_go_version))]
+    go_min_version: u32,
+}
+
+/// Parse a Go version of the form "1.<minor>" into its minor version.
+fn parse_go_version(version: &str) -> Result<u32, String> {
+    let minor = version
+        .strip_prefix("1.")
+        .and_then(|minor| minor.parse::<u32>().ok())
+        .ok_or_else(|| format!("invalid Go version {:?}, expected e.g. \"1.18\"", version))?;
+    if minor < aptos_sdk_builder::golang::OLDEST_GO_VERSION {
+        return Err(format!(
+            "the generated Go code requires Go 1.{} or later",
+            aptos_sdk_builder::golang::OLDEST_GO_VERSION
+        ));
+    }
+    Ok(minor)
 }
 
 fn main() {
-    let options = Options::from_args();
+    let mut options = Options::from_args();
+    if options.package_name.is_none() {
+        options.package_name = options.module_path.clone();
+    }
+    let go_enum_style = match options.go_enum_style {
+        GoEnumStyle::Interface => aptos_sdk_builder::golang::EnumStyle::Interface,
+        GoEnumStyle::Visitor => aptos_sdk_builder::golang::EnumStyle::Visitor,
+    };
     let abis = aptos_sdk_builder::read_abis(&options.abi_directories)
         .expect("Failed to read ABI in directory");
 
@@ -76,12 +122,13 @@ fn main() {
                         .unwrap()
                 }
                 Language::Go => {
-                    aptos_sdk_builder::golang::output(
+                    aptos_sdk_builder::golang::output_with_enum_style(
                         &mut out,
                         options.serde_package_name.clone(),
                         options.package_name.clone(),
                         options.module_name.as_deref().unwrap_or("main").to_string(),
                         &abis,
+                        go_enum_style,
                     )
                     .unwrap();
                 }
@@ -91,6 +138,13 @@ fn main() {
         Some(dir) => dir,
     };
 
+    if let (Language::Go, Some(module_path)) = (&options.language, &options.module_path) {
+        aptos_sdk_builder::golang::Installer::new(install_dir.clone(), None, None)
+            .with_min_go_version(options.go_min_version)
+            .install_go_mod(module_path)
+            .unwrap();
+    }
+
     // Aptos types
     if let Some(registry_file) = options.with_aptos_types {
         let installer: Box<dyn serdegen::SourceInstaller<Error = Box<dyn std::error::Error>>> =
@@ -126,6 +180,17 @@ fn main() {
             .with_encodings(vec![serdegen::Encoding::Bcs]);
 
         installer.install_module(&config, &registry).unwrap();
+
+        if let Language::Go = options.language {
+            aptos_sdk_builder::golang::Installer::new(
+                install_dir.clone(),
+                options.serde_package_name.clone(),
+                options.package_name.clone(),
+            )
+            .with_min_go_version(options.go_min_version)
+            .install_aptos_types_runtime()
+            .unwrap();
+        }
     }
 
     // Transaction builders
@@ -135,11 +200,15 @@ fn main() {
                 install_dir,
                 options.aptos_version_number,
             )),
-            Language::Go => Box::new(aptos_sdk_builder::golang::Installer::new(
-                install_dir,
-                options.serde_package_name,
-                options.package_name,
-            )),
+            Language::Go => Box::new(
+                aptos_sdk_builder::golang::Installer::new(
+                    install_dir,
+                    options.serde_package_name,
+                    options.package_name,
+                )
+                .with_enum_style(go_enum_style)
+                .with_min_go_version(options.go_min_version),
+            ),
         };
 
     if let Some(name) = options.module_name {
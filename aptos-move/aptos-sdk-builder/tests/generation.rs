@@ -1,13 +1,28 @@
 // Copyright (c) Aptos
 // SPDX-License-Identifier: Apache-2.0
 
+use aptos_crypto::{ed25519::Ed25519PrivateKey, HashValue, PrivateKey};
 use aptos_sdk_builder as buildgen;
-use aptos_types::transaction::ScriptABI;
-use cached_framework_packages::abis;
+use aptos_sdk_builder::SourceInstaller as _;
+use aptos_types::{
+    account_config::CORE_CODE_ADDRESS,
+    block_metadata::BlockMetadata,
+    chain_id::ChainId,
+    transaction::{
+        authenticator::AuthenticationKey, ModuleBundle, RawTransaction, Script, ScriptABI,
+        ScriptFunction, Transaction, TransactionArgument, TransactionPayload,
+    },
+};
+use cached_framework_packages::{abis, aptos_stdlib};
+use move_deps::move_core_types::{
+    account_address::AccountAddress,
+    identifier::Identifier,
+    language_storage::{ModuleId, StructTag, TypeTag},
+};
 use serde_generate as serdegen;
 use serde_generate::SourceInstaller as _;
 use serde_reflection::Registry;
-use std::{io::Write, process::Command};
+use std::{convert::TryFrom, io::Write, path::Path, process::Command};
 use tempfile::tempdir;
 
 fn get_aptos_registry() -> Registry {
@@ -95,3 +110,380 @@ fn test_that_rust_script_fun_code_compiles() {
         EXPECTED_SCRIPT_FUN_OUTPUT,
     );
 }
+
+#[test]
+fn test_that_go_packages_use_module_path() {
+    let dir = tempdir().unwrap();
+    let module_path = "github.com/myorg/aptos-bindings";
+    let installer = buildgen::golang::Installer::new(
+        dir.path().to_path_buf(),
+        None,
+        Some(module_path.to_string()),
+    );
+    installer.install_go_mod(module_path).unwrap();
+    installer
+        .install_transaction_builders("aptosstdlib", &get_script_fun_abis())
+        .unwrap();
+
+    let go_mod = std::fs::read_to_string(dir.path().join("go.mod")).unwrap();
+    assert!(go_mod.starts_with("module github.com/myorg/aptos-bindings\n"));
+    let lib = std::fs::read_to_string(dir.path().join("aptosstdlib/lib.go")).unwrap();
+    assert!(lib.contains("\"github.com/myorg/aptos-bindings/aptostypes\""));
+    let tests = std::fs::read_to_string(dir.path().join("aptosstdlib/lib_test.go")).unwrap();
+    assert!(tests.contains("\"github.com/myorg/aptos-bindings/aptostypes\""));
+    assert!(tests.contains("func TestScriptFunctionRoundTrip(t *testing.T) {"));
+    assert!(tests.contains("func BenchmarkDecoderCache(b *testing.B) {"));
+}
+
+#[test]
+fn test_that_go_visitors_are_opt_in() {
+    let dir = tempdir().unwrap();
+    let installer = buildgen::golang::Installer::new(dir.path().to_path_buf(), None, None);
+    installer
+        .install_transaction_builders("interfaces", &get_script_fun_abis())
+        .unwrap();
+    installer
+        .with_enum_style(buildgen::golang::EnumStyle::Visitor)
+        .install_transaction_builders("visitors", &get_script_fun_abis())
+        .unwrap();
+
+    let lib = std::fs::read_to_string(dir.path().join("interfaces/lib.go")).unwrap();
+    assert!(!lib.contains("Visitor"));
+    let lib = std::fs::read_to_string(dir.path().join("visitors/lib.go")).unwrap();
+    assert!(lib.contains("type ScriptFunctionCallVisitor[R any] interface {"));
+    assert!(lib.contains("func VisitScriptFunctionCall[R any]("));
+}
+
+#[test]
+fn test_that_go_generics_are_gated_for_older_go_versions() {
+    let dir = tempdir().unwrap();
+    let installer = buildgen::golang::Installer::new(dir.path().to_path_buf(), None, None)
+        .with_enum_style(buildgen::golang::EnumStyle::Visitor)
+        .with_min_go_version(16);
+    installer.install_go_mod("testing").unwrap();
+    installer.install_aptos_types_runtime().unwrap();
+    installer
+        .install_transaction_builders("visitors", &get_script_fun_abis())
+        .unwrap();
+
+    let go_mod = std::fs::read_to_string(dir.path().join("go.mod")).unwrap();
+    assert!(go_mod.ends_with("\ngo 1.16\n"));
+    let lib = std::fs::read_to_string(dir.path().join("visitors/lib.go")).unwrap();
+    assert!(!lib.contains("Visitor"));
+    let visitors = std::fs::read_to_string(dir.path().join("visitors/visitors.go")).unwrap();
+    assert!(visitors.contains("\n//go:build go1.18\n// +build go1.18\n\npackage visitors\n"));
+    assert!(visitors.contains("func VisitScriptFunctionCall[R any]("));
+    for name in &["maps.go", "option.go", "sequences.go", "fuzz_test.go"] {
+        let code = std::fs::read_to_string(dir.path().join("aptostypes").join(name)).unwrap();
+        assert!(code.contains("\n//go:build go1.18\n// +build go1.18\n"));
+    }
+}
+
+#[test]
+fn test_that_go_code_has_generated_header() {
+    let abis = get_script_fun_abis();
+    let mut lib = Vec::new();
+    buildgen::golang::output(&mut lib, None, None, "aptosstdlib".to_string(), &abis).unwrap();
+    let lib = String::from_utf8(lib).unwrap();
+    let header = lib.lines().next().unwrap();
+    assert!(header.starts_with("// Code generated by transaction-builder-generator "));
+    assert!(header.ends_with("; DO NOT EDIT."));
+
+    let mut other = Vec::new();
+    buildgen::golang::output(
+        &mut other,
+        None,
+        None,
+        "aptosstdlib".to_string(),
+        &abis[1..],
+    )
+    .unwrap();
+    let other = String::from_utf8(other).unwrap();
+    assert_ne!(lib.lines().nth(1), other.lines().nth(1));
+}
+
+#[test]
+fn test_that_go_code_is_gofmt_clean() {
+    // The generator leaves the code unformatted without gofmt, so there is nothing to check.
+    which::which("gofmt").expect("gofmt must be installed to check the generated Go code");
+    let registry = get_aptos_registry();
+    let dir = tempdir().unwrap();
+
+    let installer = serdegen::golang::Installer::new(dir.path().to_path_buf(), None);
+    let config = serdegen::CodeGeneratorConfig::new("aptostypes".to_string())
+        .with_encodings(vec![serdegen::Encoding::Bcs]);
+    installer.install_module(&config, &registry).unwrap();
+
+    let installer = buildgen::golang::Installer::new(dir.path().to_path_buf(), None, None);
+    installer.install_aptos_types_runtime().unwrap();
+    installer
+        .install_transaction_builders("aptosstdlib", &get_script_fun_abis())
+        .unwrap();
+
+    let output = Command::new("gofmt")
+        .arg("-l")
+        .arg(dir.path())
+        .output()
+        .unwrap();
+    assert!(output.status.success());
+    assert_eq!(std::str::from_utf8(&output.stdout).unwrap(), "");
+}
+
+#[test]
+fn test_that_go_unknown_variant_errors_wrap_err_unknown_variant() {
+    let registry = get_aptos_registry();
+    let dir = tempdir().unwrap();
+
+    let installer = serdegen::golang::Installer::new(dir.path().to_path_buf(), None);
+    let config = serdegen::CodeGeneratorConfig::new("aptostypes".to_string())
+        .with_encodings(vec![serdegen::Encoding::Bcs]);
+    installer.install_module(&config, &registry).unwrap();
+
+    let installer = buildgen::golang::Installer::new(dir.path().to_path_buf(), None, None);
+    installer.install_aptos_types_runtime().unwrap();
+
+    let lib = std::fs::read_to_string(dir.path().join("aptostypes/lib.go")).unwrap();
+    assert!(lib.contains(
+        "return nil, fmt.Errorf(\"%w index for TypeTag: %d\", ErrUnknownVariant, index)"
+    ));
+    assert!(!lib.contains("Unknown variant index"));
+}
+
+const SIGN_DEMO_KEY: &str = "0101010101010101010101010101010101010101010101010101010101010101";
+
+const EXPECTED_SIGN_DEMO_OUTPUT: &str = "7df415e5b21bdaa8b2946e8f1f4278b39904e51a69627494cd3e6f2996732fbd000000000000000003000000000000000000000000000000000000000000000000000000000000000104636f696e087472616e73666572010700000000000000000000000000000000000000000000000000000000000000010a6170746f735f636f696e094170746f73436f696e00022000000000000000000000000000000000000000000000000000000000000022220887d6120000000000d007000000000000010000000000000000f15365000000000400208a88e3dd7409f195fd52db2d3cba5d72ca6709bf1d94121bf3748801b40f6f5c40e1ce917e87d0d820271380c36fb9da93740ff059cebe161f25ca3fb3aba8d2173746c0533e17031ce97ec02e31bc6c3eaa7a36a99089ec8b8e6b4f0a330cc00c
+0xbb7ad48f631fe262770318874ebc7aab15b0de6308e24787c2a1bbe9fb945f60
+";
+
+/// Install the generated Go packages as the module "testing", with the Go program
+/// `main_file` as the package `testing/<command>`, and resolve the dependencies.
+fn install_go_command(dir: &Path, main_file: &str, command: &str) {
+    let registry = get_aptos_registry();
+    let installer = serdegen::golang::Installer::new(dir.to_path_buf(), None);
+    let config = serdegen::CodeGeneratorConfig::new("aptostypes".to_string())
+        .with_encodings(vec![serdegen::Encoding::Bcs]);
+    installer.install_module(&config, &registry).unwrap();
+
+    let installer =
+        buildgen::golang::Installer::new(dir.to_path_buf(), None, Some("testing".into()));
+    installer.install_go_mod("testing").unwrap();
+    installer.install_aptos_types_runtime().unwrap();
+    installer
+        .install_transaction_builders("aptosstdlib", &get_script_fun_abis())
+        .unwrap();
+    std::fs::create_dir(dir.join(command)).unwrap();
+    std::fs::copy(main_file, dir.join(command).join("main.go")).unwrap();
+
+    let status = Command::new("go")
+        .current_dir(dir)
+        .arg("mod")
+        .arg("tidy")
+        .status()
+        .unwrap();
+    assert!(status.success());
+}
+
+#[test]
+fn test_that_go_sign_demo_runs() {
+    if which::which("go").is_err() {
+        return;
+    }
+    let dir = tempdir().unwrap();
+    install_go_command(dir.path(), "examples/golang/sign_demo.go", "sign_demo");
+
+    let output = Command::new("go")
+        .current_dir(dir.path())
+        .arg("run")
+        .arg("./sign_demo")
+        .arg(SIGN_DEMO_KEY)
+        .output()
+        .unwrap();
+    assert!(output.status.success());
+    assert_eq!(
+        std::str::from_utf8(&output.stdout).unwrap(),
+        EXPECTED_SIGN_DEMO_OUTPUT
+    );
+}
+
+/// The Rust SDK must produce the same bytes and hash as the Go sign demo for the same inputs,
+/// so that the output of both can be compared when debugging.
+#[test]
+fn test_that_rust_sdk_matches_go_sign_demo() {
+    let seed = (0..SIGN_DEMO_KEY.len())
+        .step_by(2)
+        .map(|i| u8::from_str_radix(&SIGN_DEMO_KEY[i..i + 2], 16).unwrap())
+        .collect::<Vec<_>>();
+    let private_key = Ed25519PrivateKey::try_from(seed.as_slice()).unwrap();
+    let sender = AuthenticationKey::ed25519(&private_key.public_key()).derived_address();
+    let raw_txn = RawTransaction::new(
+        sender,
+        0,
+        aptos_stdlib::aptos_coin_transfer(
+            AccountAddress::from_hex_literal("0x2222").unwrap(),
+            1_234_567,
+        ),
+        2_000,
+        1,
+        1_700_000_000,
+        ChainId::test(),
+    );
+    let signed_txn = raw_txn
+        .sign(&private_key, private_key.public_key())
+        .unwrap()
+        .into_inner();
+    let bytes = bcs::to_bytes(&signed_txn)
+        .unwrap()
+        .iter()
+        .map(|b| format!("{:02x}", b))
+        .collect::<String>();
+    let output = format!("{}\n0x{}\n", bytes, signed_txn.committed_hash().to_hex());
+    assert_eq!(output, EXPECTED_SIGN_DEMO_OUTPUT);
+}
+
+/// Values encoded by the Rust BCS implementation, by name of the Go type to decode them.
+fn get_bcs_fixtures() -> Vec<(&'static str, Vec<u8>)> {
+    let coin = TypeTag::Struct(StructTag {
+        address: CORE_CODE_ADDRESS,
+        module: Identifier::new("aptos_coin").unwrap(),
+        name: Identifier::new("AptosCoin").unwrap(),
+        type_params: vec![],
+    });
+    let receiver = AccountAddress::from_hex_literal("0x2222").unwrap();
+    let script_function = ScriptFunction::new(
+        ModuleId::new(CORE_CODE_ADDRESS, Identifier::new("coin").unwrap()),
+        Identifier::new("transfer").unwrap(),
+        vec![coin.clone()],
+        vec![
+            bcs::to_bytes(&receiver).unwrap(),
+            bcs::to_bytes(&1_234_567u64).unwrap(),
+        ],
+    );
+    let script = Script::new(
+        vec![0xa1, 0x1c, 0xeb, 0x0b],
+        vec![
+            TypeTag::Vector(Box::new(coin)),
+            TypeTag::U8,
+            TypeTag::Signer,
+        ],
+        vec![
+            TransactionArgument::U8(1),
+            TransactionArgument::U64(u64::MAX),
+            TransactionArgument::U128(u128::MAX),
+            TransactionArgument::Address(receiver),
+            TransactionArgument::U8Vector(vec![0; 300]),
+            TransactionArgument::Bool(true),
+        ],
+    );
+    let private_key = Ed25519PrivateKey::try_from(&[1u8; 32][..]).unwrap();
+    let raw_txn = RawTransaction::new(
+        receiver,
+        7,
+        TransactionPayload::ScriptFunction(script_function.clone()),
+        2_000,
+        1,
+        1_700_000_000,
+        ChainId::new(4),
+    );
+    let signed_txn = raw_txn
+        .sign(&private_key, private_key.public_key())
+        .unwrap()
+        .into_inner();
+    // Several sequences in a row, where a wrong field order cannot go unnoticed.
+    let block_metadata = BlockMetadata::new(
+        HashValue::sha3_256_of(b"block"),
+        3,
+        42,
+        vec![true, false, true],
+        receiver,
+        vec![0, 7, u32::MAX],
+        1_700_000_000_000_000,
+    );
+
+    vec![
+        (
+            "TransactionPayload",
+            bcs::to_bytes(&TransactionPayload::ScriptFunction(script_function)).unwrap(),
+        ),
+        (
+            "TransactionPayload",
+            bcs::to_bytes(&TransactionPayload::Script(script.clone())).unwrap(),
+        ),
+        (
+            "TransactionPayload",
+            bcs::to_bytes(&TransactionPayload::ModuleBundle(ModuleBundle::new(vec![
+                vec![0xa1, 0x1c, 0xeb, 0x0b],
+            ])))
+            .unwrap(),
+        ),
+        ("Script", bcs::to_bytes(&script).unwrap()),
+        ("SignedTransaction", bcs::to_bytes(&signed_txn).unwrap()),
+        ("BlockMetadata", bcs::to_bytes(&block_metadata).unwrap()),
+        (
+            "Transaction",
+            bcs::to_bytes(&Transaction::BlockMetadata(block_metadata)).unwrap(),
+        ),
+    ]
+}
+
+/// A struct type with several type parameters, and its canonical form in Go.
+fn get_multi_generic_type_tag() -> (TypeTag, &'static str) {
+    let coin = TypeTag::Struct(StructTag {
+        address: CORE_CODE_ADDRESS,
+        module: Identifier::new("aptos_coin").unwrap(),
+        name: Identifier::new("AptosCoin").unwrap(),
+        type_params: vec![],
+    });
+    let pool = TypeTag::Struct(StructTag {
+        address: CORE_CODE_ADDRESS,
+        module: Identifier::new("pool").unwrap(),
+        name: Identifier::new("Pool").unwrap(),
+        type_params: vec![
+            TypeTag::U64,
+            coin,
+            TypeTag::Vector(Box::new(TypeTag::U8)),
+            TypeTag::Bool,
+        ],
+    });
+    (
+        pool,
+        "0x1::pool::Pool<u64, 0x1::aptos_coin::AptosCoin, vector<u8>, bool>",
+    )
+}
+
+#[test]
+fn test_that_go_code_round_trips_rust_bcs() {
+    if which::which("go").is_err() {
+        return;
+    }
+    let dir = tempdir().unwrap();
+    install_go_command(
+        dir.path(),
+        "tests/golang/bcs_round_trip.go",
+        "bcs_round_trip",
+    );
+    for (index, (type_name, bytes)) in get_bcs_fixtures().into_iter().enumerate() {
+        let fixture_dir = dir.path().join("fixtures").join(type_name);
+        std::fs::create_dir_all(&fixture_dir).unwrap();
+        std::fs::write(fixture_dir.join(format!("{}.bcs", index)), bytes).unwrap();
+    }
+    // The type parameters of a struct type must keep their order through decoding, which a
+    // byte-for-byte round trip alone does not show.
+    let (pool, pool_string) = get_multi_generic_type_tag();
+    let type_tag_dir = dir.path().join("fixtures").join("TypeTag");
+    std::fs::create_dir_all(&type_tag_dir).unwrap();
+    std::fs::write(type_tag_dir.join("pool.bcs"), bcs::to_bytes(&pool).unwrap()).unwrap();
+    std::fs::write(type_tag_dir.join("pool.txt"), pool_string).unwrap();
+
+    let output = Command::new("go")
+        .current_dir(dir.path())
+        .arg("run")
+        .arg("./bcs_round_trip")
+        .arg("fixtures")
+        .output()
+        .unwrap();
+    assert!(
+        output.status.success(),
+        "{}",
+        std::str::from_utf8(&output.stderr).unwrap()
+    );
+}
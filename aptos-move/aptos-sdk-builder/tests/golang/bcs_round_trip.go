@@ -0,0 +1,94 @@
+// Copyright (c) Aptos
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	aptos "testing/aptostypes"
+)
+
+type serializable interface {
+	BcsSerialize() ([]byte, error)
+}
+
+// Decoders of the fixtures, by name of the directory holding them.
+var decoders = map[string]func([]byte) (serializable, error){
+	"BlockMetadata": func(input []byte) (serializable, error) {
+		obj, err := aptos.BcsDeserializeBlockMetadata(input)
+		return &obj, err
+	},
+	"Transaction": func(input []byte) (serializable, error) {
+		return aptos.BcsDeserializeTransaction(input)
+	},
+	"TransactionPayload": func(input []byte) (serializable, error) {
+		return aptos.BcsDeserializeTransactionPayload(input)
+	},
+	"Script": func(input []byte) (serializable, error) {
+		obj, err := aptos.BcsDeserializeScript(input)
+		return &obj, err
+	},
+	"SignedTransaction": func(input []byte) (serializable, error) {
+		obj, err := aptos.BcsDeserializeSignedTransaction(input)
+		return &obj, err
+	},
+	"TypeTag": func(input []byte) (serializable, error) {
+		return aptos.BcsDeserializeTypeTag(input)
+	},
+}
+
+// Usage: bcs_round_trip <fixtures dir>
+//
+// Decodes every `<fixtures dir>/<type>/*.bcs` file produced by the Rust BCS implementation,
+// encodes the value again and fails unless the bytes are identical. If there is a
+// `<name>.txt` file next to `<name>.bcs`, the decoded value must also print as its content.
+func main() {
+	paths, err := filepath.Glob(filepath.Join(os.Args[1], "*", "*.bcs"))
+	if err != nil || len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "no fixtures found: %v\n", err)
+		os.Exit(1)
+	}
+	failed := false
+	for _, path := range paths {
+		if err := roundTrip(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Printf("%d fixtures round-tripped\n", len(paths))
+}
+
+func roundTrip(path string) error {
+	decode, ok := decoders[filepath.Base(filepath.Dir(path))]
+	if !ok {
+		return fmt.Errorf("unknown fixture type")
+	}
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	obj, err := decode(input)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize: %v", err)
+	}
+	if expected, err := os.ReadFile(strings.TrimSuffix(path, ".bcs") + ".txt"); err == nil {
+		if decoded := fmt.Sprint(obj); decoded != strings.TrimSpace(string(expected)) {
+			return fmt.Errorf("decoded value mismatch:\n  rust: %s\n  go:   %s", expected, decoded)
+		}
+	}
+	output, err := obj.BcsSerialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize: %v", err)
+	}
+	if !bytes.Equal(input, output) {
+		return fmt.Errorf("round trip mismatch:\n  rust: %x\n  go:   %x", input, output)
+	}
+	return nil
+}